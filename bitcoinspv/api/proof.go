@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+)
+
+// proofTimeout limits the node and light client queries building a proof.
+const proofTimeout = 30 * time.Second
+
+// Prover builds the SPV proofs served by the proof endpoints.
+type Prover interface {
+	TxProof(ctx context.Context, blockHash *chainhash.Hash, txID chainhash.Hash) (*bitcoinspv.TxProof, error)
+}
+
+// ErrorResponse is the body of the JSON endpoints errors.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type proofHandler struct {
+	prover Prover
+	logger zerolog.Logger
+}
+
+// NewProofHandler returns the handler of the proof endpoints, to be registered on the
+// /proof/ path:
+//
+//	GET /proof/{txid}: the block including the transaction is looked up with the node.
+//	GET /proof/{blockhash}/{txid}
+func NewProofHandler(prover Prover, parentLogger zerolog.Logger) http.Handler {
+	h := &proofHandler{
+		prover: prover,
+		logger: parentLogger.With().Str("module", "api").Logger(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /proof/{txid}", h.handleProof)
+	mux.HandleFunc("GET /proof/{blockhash}/{txid}", h.handleProof)
+	return mux
+}
+
+func (h *proofHandler) handleProof(w http.ResponseWriter, req *http.Request) {
+	txID, err := chainhash.NewHashFromStr(req.PathValue("txid"))
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid txid: " + err.Error()})
+		return
+	}
+	var blockHash *chainhash.Hash
+	if s := req.PathValue("blockhash"); s != "" {
+		if blockHash, err = chainhash.NewHashFromStr(s); err != nil {
+			WriteJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid block hash: " + err.Error()})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), proofTimeout)
+	defer cancel()
+	proof, err := h.prover.TxProof(ctx, blockHash, *txID)
+	if err != nil {
+		code := proofErrorCode(err)
+		if code == http.StatusInternalServerError {
+			h.logger.Err(err).Str("txid", txID.String()).Msg("Failed to build proof")
+		}
+		WriteJSON(w, code, ErrorResponse{Error: err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, proof)
+}
+
+func proofErrorCode(err error) int {
+	switch {
+	case errors.Is(err, clients.ErrTxNotFound), errors.Is(err, clients.ErrBlockNotFound),
+		errors.Is(err, types.ErrTxNotInBlock),
+		errors.Is(err, bitcoinspv.ErrBlockNotInLC):
+		return http.StatusNotFound
+	case errors.Is(err, bitcoinspv.ErrNotEnoughConfirmations), errors.Is(err, bitcoinspv.ErrProofRejected):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
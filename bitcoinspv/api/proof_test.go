@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProver struct {
+	blockHash *chainhash.Hash
+	err       error
+}
+
+func (f *fakeProver) TxProof(_ context.Context, blockHash *chainhash.Hash, txID chainhash.Hash,
+) (*bitcoinspv.TxProof, error) {
+	f.blockHash = blockHash
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &bitcoinspv.TxProof{TxID: txID.String(), Height: 100, Confirmations: 6}, nil
+}
+
+func TestProofEndpoints(t *testing.T) {
+	prover := &fakeProver{}
+	s := NewServer("", &fakeRelayer{}, zerolog.Nop())
+	s.Handle("GET /proof/", NewProofHandler(prover, zerolog.Nop()))
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	txID := chainhash.Hash{1}
+	blockHash := chainhash.Hash{2}
+
+	tests := []struct {
+		name          string
+		path          string
+		err           error
+		wantCode      int
+		wantBlockHash *chainhash.Hash
+	}{
+		{name: "txid", path: "/proof/" + txID.String(), wantCode: http.StatusOK},
+		{name: "block hash and txid", path: fmt.Sprintf("/proof/%s/%s", blockHash, txID),
+			wantCode: http.StatusOK, wantBlockHash: &blockHash},
+		{name: "invalid txid", path: "/proof/xyz", wantCode: http.StatusBadRequest},
+		{name: "invalid block hash", path: "/proof/xyz/" + txID.String(), wantCode: http.StatusBadRequest},
+		{name: "tx not found", path: "/proof/" + txID.String(), err: clients.ErrTxNotFound,
+			wantCode: http.StatusNotFound},
+		{name: "block not found", path: fmt.Sprintf("/proof/%s/%s", blockHash, txID),
+			err: clients.ErrBlockNotFound, wantCode: http.StatusNotFound},
+		{name: "block not in light client", path: "/proof/" + txID.String(), err: bitcoinspv.ErrBlockNotInLC,
+			wantCode: http.StatusNotFound},
+		{name: "not enough confirmations", path: "/proof/" + txID.String(),
+			err: bitcoinspv.ErrNotEnoughConfirmations, wantCode: http.StatusConflict},
+		{name: "node error", path: "/proof/" + txID.String(), err: fmt.Errorf("connection refused"),
+			wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prover.err = tt.err
+			prover.blockHash = nil
+			resp, err := http.Get(srv.URL + tt.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				var e ErrorResponse
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+				assert.NotEmpty(t, e.Error)
+				return
+			}
+			assert.Equal(t, tt.wantBlockHash, prover.blockHash)
+			var proof bitcoinspv.TxProof
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&proof))
+			assert.Equal(t, txID.String(), proof.TxID)
+			assert.Equal(t, int64(6), proof.Confirmations)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Package api implements the HTTP API of the bitcoin-spv relayer.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/rs/zerolog"
+)
+
+// readyTimeout limits the light client query done by the readiness probe.
+const readyTimeout = 5 * time.Second
+
+// Relayer is the subset of the relayer used by the API.
+type Relayer interface {
+	Ready(ctx context.Context) error
+	Status(ctx context.Context) bitcoinspv.Status
+}
+
+// Server serves the relayer health, readiness and status endpoints.
+type Server struct {
+	mux     *http.ServeMux
+	srv     *http.Server
+	relayer Relayer
+	logger  zerolog.Logger
+}
+
+// NewServer creates a new API server listening on addr.
+func NewServer(addr string, relayer Relayer, parentLogger zerolog.Logger) *Server {
+	s := &Server{
+		mux:     http.NewServeMux(),
+		relayer: relayer,
+		logger:  parentLogger.With().Str("module", "api").Logger(),
+	}
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /status", s.handleStatus)
+	return s
+}
+
+// Handle registers an additional handler for the given pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the HTTP handler of the server.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start starts serving in the background.
+func (s *Server) Start() {
+	go func() {
+		s.logger.Info().Str("addr", s.srv.Addr).Msg("Serving relayer API")
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Err(err).Msg("API server failed")
+		}
+	}()
+}
+
+// Shutdown gracefully stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), readyTimeout)
+	defer cancel()
+
+	if err := s.relayer.Ready(ctx); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
+	WriteJSON(w, http.StatusOK, s.relayer.Status(req.Context()))
+}
+
+// WriteJSON writes v as a JSON response with the given status code.
+func WriteJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
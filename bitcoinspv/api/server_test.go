@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRelayer struct {
+	readyErr error
+	status   bitcoinspv.Status
+}
+
+func (f *fakeRelayer) Ready(context.Context) error { return f.readyErr }
+
+func (f *fakeRelayer) Status(context.Context) bitcoinspv.Status { return f.status }
+
+func TestEndpoints(t *testing.T) {
+	relayer := &fakeRelayer{
+		readyErr: errors.New("bootstrap not finished"),
+		status:   bitcoinspv.Status{BTCTipHeight: 110, LCTipHeight: 100, CacheFirstHeight: 95, CacheLastHeight: 110},
+	}
+	srv := httptest.NewServer(NewServer("", relayer, zerolog.Nop()).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		ready    error
+		wantCode int
+	}{
+		{name: "healthz", path: "/healthz", ready: relayer.readyErr, wantCode: http.StatusOK},
+		{name: "readyz not ready", path: "/readyz", ready: relayer.readyErr, wantCode: http.StatusServiceUnavailable},
+		{name: "readyz ready", path: "/readyz", ready: nil, wantCode: http.StatusOK},
+		{name: "status", path: "/status", wantCode: http.StatusOK},
+		{name: "unknown", path: "/unknown", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer.readyErr = tt.ready
+			resp, err := http.Get(srv.URL + tt.path)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	resp, err := http.Get(srv.URL + "/status")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	var st bitcoinspv.Status
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+	assert.Equal(t, relayer.status, st)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/rs/zerolog"
+)
+
+// WalrusIndex is the index of the blocks stored in Walrus, implemented by the state store.
+type WalrusIndex interface {
+	GetWalrusBlob(ctx context.Context, blockHash []byte) (*store.WalrusBlob, error)
+	ListWalrusBlobs(ctx context.Context, fromHeight, toHeight int64) ([]*store.WalrusBlob, error)
+}
+
+type walrusHandler struct {
+	index  WalrusIndex
+	logger zerolog.Logger
+}
+
+// NewWalrusHandler returns the handler of the Walrus blob lookup endpoints, to be registered
+// on the /walrus/ path:
+//
+//	GET /walrus/height/{height}: the blobs of the blocks stored at the height, several
+//	  when the stored blocks were reorganized.
+//	GET /walrus/block/{hash}
+func NewWalrusHandler(index WalrusIndex, parentLogger zerolog.Logger) http.Handler {
+	h := &walrusHandler{
+		index:  index,
+		logger: parentLogger.With().Str("module", "api").Logger(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /walrus/height/{height}", h.handleHeight)
+	mux.HandleFunc("GET /walrus/block/{hash}", h.handleBlock)
+	return mux
+}
+
+func (h *walrusHandler) handleHeight(w http.ResponseWriter, req *http.Request) {
+	height, err := strconv.ParseInt(req.PathValue("height"), 10, 64)
+	if err != nil || height < 0 {
+		WriteJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid height"})
+		return
+	}
+	blobs, err := h.index.ListWalrusBlobs(req.Context(), height, height)
+	if err != nil {
+		h.logger.Err(err).Int64("height", height).Msg("Failed to list Walrus blobs")
+		WriteJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(blobs) == 0 {
+		WriteJSON(w, http.StatusNotFound, ErrorResponse{Error: "no block stored at this height"})
+		return
+	}
+	infos := make([]bitcoinspv.WalrusBlobInfo, len(blobs))
+	for i := range blobs {
+		infos[i] = bitcoinspv.NewWalrusBlobInfo(blobs[i])
+	}
+	WriteJSON(w, http.StatusOK, infos)
+}
+
+func (h *walrusHandler) handleBlock(w http.ResponseWriter, req *http.Request) {
+	hash, err := chainhash.NewHashFromStr(req.PathValue("hash"))
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid block hash: " + err.Error()})
+		return
+	}
+	blob, err := h.index.GetWalrusBlob(req.Context(), hash[:])
+	if err != nil {
+		h.logger.Err(err).Str("hash", hash.String()).Msg("Failed to get Walrus blob")
+		WriteJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if blob == nil {
+		WriteJSON(w, http.StatusNotFound, ErrorResponse{Error: "block not stored"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, bitcoinspv.NewWalrusBlobInfo(blob))
+}
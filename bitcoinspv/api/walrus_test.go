@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalrusEndpoints(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+	hashA, hashB := chainhash.Hash{1}, chainhash.Hash{2}
+	for _, b := range []store.WalrusBlob{
+		{BlockHash: hashA[:], Height: 100, BlobID: "blobA", Cost: 10, EndEpoch: 5, Timestamp: 1},
+		{BlockHash: hashB[:], Height: 100, BlobID: "blobB", EndEpoch: 6, Timestamp: 2},
+	} {
+		require.NoError(t, db.UpsertWalrusBlob(ctx, b))
+	}
+
+	s := NewServer("", &fakeRelayer{}, zerolog.Nop())
+	s.Handle("GET /walrus/", NewWalrusHandler(db, zerolog.Nop()))
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	get := func(t *testing.T, path string, wantCode int, out any) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, wantCode, resp.StatusCode)
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+
+	t.Run("height", func(t *testing.T) {
+		var infos []bitcoinspv.WalrusBlobInfo
+		get(t, "/walrus/height/100", http.StatusOK, &infos)
+		require.Len(t, infos, 2)
+		assert.Equal(t, "blobA", infos[0].BlobID)
+		assert.Equal(t, hashA.String(), infos[0].BlockHash)
+		assert.Equal(t, int64(10), infos[0].Cost)
+		assert.Equal(t, "blobB", infos[1].BlobID)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		var info bitcoinspv.WalrusBlobInfo
+		get(t, "/walrus/block/"+hashB.String(), http.StatusOK, &info)
+		assert.Equal(t, "blobB", info.BlobID)
+		assert.Equal(t, int64(100), info.Height)
+		assert.Equal(t, int64(6), info.EndEpoch)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var e ErrorResponse
+		get(t, "/walrus/height/101", http.StatusNotFound, &e)
+		get(t, "/walrus/block/"+(chainhash.Hash{3}).String(), http.StatusNotFound, &e)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var e ErrorResponse
+		get(t, "/walrus/height/abc", http.StatusBadRequest, &e)
+		get(t, "/walrus/block/xyz", http.StatusBadRequest, &e)
+	})
+}
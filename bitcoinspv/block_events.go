@@ -2,10 +2,14 @@ package bitcoinspv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
 	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/prometheus/client_golang/prometheus"
 
 	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
 )
@@ -19,20 +23,24 @@ func (r *Relayer) onBlockEvent() {
 		case blockEvent, openChan := <-r.btcClient.BlockEventChannel():
 			if !openChan {
 				r.logger.Error().Msg("BTC Block event channel is now closed")
+				r.state.setSubscribed(false)
 				return
 			}
+			r.state.blockEventReceived()
 
 			if err := r.handleBlockEvent(blockEvent); err != nil {
 				r.logger.Warn().Msgf(
 					"Error in event processing: %v, restarting bootstrap",
 					err,
 				)
-				// We call the bootstrap here, every time there is an error when processing evetns.
-				// This usually happens when there is a reorg and the new blocks that are received
-				// through ZMQ do not extend the chain known to the lgiht client.
-				// Then we need to re-send all the blocks starting from the latest common ancestor.
+				// Reorgs and missed events are handled incrementally by handleReorg. The bootstrap
+				// is the last resort, when the cache can't be reconciled with the node (e.g. the
+				// common ancestor is older than the cache or the node switched branch again).
+				metrics.BootstrapRestarts.Inc()
 				r.multitryBootstrap(true)
 			}
+		case <-r.electedChan():
+			r.onElected()
 		case <-r.quitChan():
 			return
 		}
@@ -53,31 +61,20 @@ func (r *Relayer) handleBlockEvent(blockEvent *btctypes.BlockEvent) error {
 
 // onConnectedBlock handles connected blocks from the BTC client.
 // It is invoked when a new connected block is received from the Bitcoin node.
+// Blocks that don't extend the cache tip (reorg or missed events) are handled by handleReorg.
 func (r *Relayer) onConnectedBlock(blockEvent *btctypes.BlockEvent) error {
+	metrics.SetBTCTip(blockEvent.Height)
 	if err := r.checkBlockValidity(blockEvent); err != nil {
+		if errors.Is(err, errCacheOutOfSync) {
+			r.logger.Info().Err(err).Msg("Cache out of sync, looking for the common ancestor")
+			return r.handleReorg(blockEvent)
+		}
 		return err
 	}
 
-	var ib *types.IndexedBlock
-	var err error
-
-	fetchFullBlocks := r.btcIndexer != nil || r.walrusHandler != nil
-	if fetchFullBlocks {
-		h := blockEvent.BlockHeader.BlockHash()
-		ib, err = r.btcClient.GetBTCBlockByHash(&h)
-		// TODO: handle retry
-		if err != nil {
-			return fmt.Errorf("failed to get full block %s by hash: %w", h.String(), err)
-		}
-		ctx := context.TODO()
-		if err := r.handleFullBlock(ctx, ib); err != nil {
-			r.logger.Error().Err(err).Msg("failed to process full block")
-		}
-	} else {
-		ib = &types.IndexedBlock{
-			BlockHeight: blockEvent.Height,
-			MsgBlock:    wire.NewMsgBlock(blockEvent.BlockHeader),
-		}
+	ib, err := r.indexBlock(blockEvent.BlockHeader, blockEvent.Height)
+	if err != nil {
+		return err
 	}
 	err = r.btcCache.Add(ib)
 	if err != nil {
@@ -87,19 +84,47 @@ func (r *Relayer) onConnectedBlock(blockEvent *btctypes.BlockEvent) error {
 	return r.processBlock(ib)
 }
 
+// indexBlock creates the IndexedBlock for a header at the given height. When the Indexer or
+// Walrus are configured, the full block is fetched from the node and handled by handleFullBlock.
+func (r *Relayer) indexBlock(header *wire.BlockHeader, height int64) (*types.IndexedBlock, error) {
+	fetchFullBlocks := r.btcIndexer != nil || r.walrusHandler != nil
+	if !fetchFullBlocks {
+		return &types.IndexedBlock{
+			BlockHeight: height,
+			MsgBlock:    wire.NewMsgBlock(header),
+		}, nil
+	}
+
+	h := header.BlockHash()
+	ib, err := r.btcClient.GetBTCBlockByHash(&h)
+	// TODO: handle retry
+	if err != nil {
+		return nil, fmt.Errorf("failed to get full block %s by hash: %w", h.String(), err)
+	}
+	ctx := context.TODO()
+	if err := r.handleFullBlock(ctx, ib); err != nil {
+		r.logger.Error().Err(err).Msg("failed to process full block")
+	}
+	return ib, nil
+}
+
 // handleFullBlock is a helper function that process a single full block.
 // It sends the block to Walrus or/and the Indexer if they are configured.
 func (r *Relayer) handleFullBlock(ctx context.Context, block *types.IndexedBlock) error {
-	if r.walrusHandler != nil {
-		r.logger.Info().Int64("height", block.BlockHeight).Msg("Storing block to Walrus")
-		r.UploadToWalrus(block.MsgBlock, block.BlockHeight, block.BlockHash().String())
+	if r.walrusQueue != nil {
+		r.logger.Info().Int64("height", block.BlockHeight).Msg("Queuing block for Walrus")
+		r.walrusQueue.enqueue(ctx, block.MsgBlock, block.BlockHeight)
 	}
 
 	if r.btcIndexer != nil {
 		r.logger.Info().Int64("height", block.BlockHeight).Msg("Sending block to Indexer")
-		if err := r.btcIndexer.SendBlocks(ctx, []*types.IndexedBlock{block}); err != nil {
+		timer := prometheus.NewTimer(metrics.IndexerUploadDuration)
+		err := r.btcIndexer.SendBlocks(ctx, []*types.IndexedBlock{block})
+		timer.ObserveDuration()
+		if err != nil {
 			return fmt.Errorf("indexer send failed: %w", err)
 		}
+		r.saveCheckpoint(ctx, store.CheckpointIndexer, block.BlockHeight, block.BlockHash())
 	}
 	return nil
 }
@@ -109,8 +134,8 @@ func (r *Relayer) handleFullBlock(ctx context.Context, block *types.IndexedBlock
 //  1. Checks if cache is empty
 //  2. Skips verify if a new block not old enough (new block height < first block in cache)
 //  3. Checks if appending a new block to cache is possible
-//  4. Checks if the new block is part of new chain (reorg),
-//     If so returns a specific error, this error handled by the caller and bootstrap process for the cache restarted.
+//  4. Checks if the new block is part of new chain (reorg) or if some blocks were missed,
+//     If so returns an error wrapping errCacheOutOfSync, handled by the caller with handleReorg.
 func (r *Relayer) checkBlockValidity(b *btctypes.BlockEvent) error {
 	if r.btcCache.IsEmpty() {
 		return fmt.Errorf("cache is empty, restart bootstrap process")
@@ -132,10 +157,12 @@ func (r *Relayer) checkBlockValidity(b *btctypes.BlockEvent) error {
 		if l.BlockHash() == b.BlockHeader.PrevBlock {
 			return nil
 		}
-		return fmt.Errorf(
-			"cache tip height: %d is outdated for connecting block %d, bootstrap process must be restarted",
-			l.BlockHeight, b.Height,
-		)
+		return fmt.Errorf("%w: connecting block %d doesn't extend cache tip %s",
+			errCacheOutOfSync, b.Height, l.BlockHash())
+	}
+	if b.Height > l.BlockHeight+1 {
+		return fmt.Errorf("%w: connecting block %d is above cache tip height %d",
+			errCacheOutOfSync, b.Height, l.BlockHeight)
 	}
 
 	reOrg, err := r.isReOrg(b)
@@ -143,7 +170,7 @@ func (r *Relayer) checkBlockValidity(b *btctypes.BlockEvent) error {
 		return err
 	}
 	if reOrg {
-		return fmt.Errorf("reorg happened at block heigh %d, bootstrap process must be restarted", b.Height)
+		return fmt.Errorf("%w: reorg happened at block height %d", errCacheOutOfSync, b.Height)
 	}
 	return nil
 }
@@ -159,6 +186,7 @@ func (r *Relayer) isReOrg(b *btctypes.BlockEvent) (bool, error) {
 	}
 	return false, nil
 }
+
 func (r *Relayer) processBlock(indexedBlock *types.IndexedBlock) error {
 	ctx, cancel := context.WithTimeout(context.Background(), r.Config.ProcessBlockTimeout)
 	defer cancel()
@@ -179,33 +207,7 @@ func (r *Relayer) processBlock(indexedBlock *types.IndexedBlock) error {
 
 // onDisconnectedBlock manages the removal of blocks
 // that have been disconnected from the Bitcoin network.
+// The disconnected block and all the blocks above it are removed from the cache.
 func (r *Relayer) onDisconnectedBlock(blockEvent *btctypes.BlockEvent) error {
-	if err := r.checkDisonnected(blockEvent); err != nil {
-		return err
-	}
-
-	if err := r.btcCache.RemoveLast(); err != nil {
-		r.logger.Warn().Msgf(
-			"Unable to delete last block from cache: %v, bootstrap process must be restarted",
-			err,
-		)
-		return err
-	}
-
-	return nil
-}
-
-func (r *Relayer) checkDisonnected(blockEvent *btctypes.BlockEvent) error {
-	tipCacheBlock := r.btcCache.Last()
-	if tipCacheBlock == nil {
-		return fmt.Errorf("no blocks found in cache, bootstrap process must be restarted")
-	}
-
-	if blockEvent.BlockHeader.BlockHash() != tipCacheBlock.BlockHash() {
-		return fmt.Errorf(
-			"cache out of sync during block disconnection, bootstrap process needs to be restarted",
-		)
-	}
-
-	return nil
+	return r.rollbackDisconnected(blockEvent.Height, blockEvent.BlockHeader.BlockHash())
 }
@@ -7,7 +7,10 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
 	relayertypes "github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -16,6 +19,7 @@ var (
 )
 
 func (r *Relayer) bootstrapRelayer(ctx context.Context, skipSubscription bool) error {
+	r.state.setBootstrapped(false)
 	if err := r.waitForBitcoinCatchup(ctx); err != nil {
 		return err
 	}
@@ -30,6 +34,7 @@ func (r *Relayer) bootstrapRelayer(ctx context.Context, skipSubscription bool) e
 
 	r.logger.Info().Msgf("BTC cache size: %d", r.btcCache.Size())
 	r.logger.Info().Msg("Successfully bootstrapped")
+	r.state.setBootstrapped(true)
 	return nil
 }
 
@@ -40,6 +45,7 @@ func (r *Relayer) setupCache(ctx context.Context, skipSubscription bool) error {
 
 	if !skipSubscription {
 		r.btcClient.SubscribeNewBlocks()
+		r.state.setSubscribed(true)
 	}
 	return nil
 }
@@ -54,7 +60,7 @@ func (r *Relayer) processAndTrimCache(ctx context.Context) error {
 }
 
 func (r *Relayer) processHeaders(ctx context.Context) error {
-	headersToProcess := r.btcCache.GetAllBlocks()
+	headersToProcess := r.resumeFromCheckpoint(ctx, r.btcCache.GetAllBlocks())
 	if _, err := r.ProcessHeaders(ctx, headersToProcess); err != nil {
 		// occurs when multiple competing spv relayers exist
 		// or when our btc node is not fully synchronized
@@ -135,11 +141,17 @@ func (r *Relayer) initializeBTCCache(ctx context.Context) error {
 		return err
 	}
 	r.btcCache = cache
+	r.state.setCache(cache)
 
-	lcHeight, err := r.getLCLatestBlockHeight(ctx)
+	lcBlock, err := r.lcClient.GetLatestBlockInfo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch light client height: %w", err)
 	}
+	lcHeight := lcBlock.Height
+	metrics.SetLCTip(lcHeight)
+	if lcBlock.Hash != nil {
+		r.saveCheckpoint(ctx, store.CheckpointLightClient, lcHeight, *lcBlock.Hash)
+	}
 
 	indexerHeight, err := r.getIndexerLatestBlockHeight()
 	if err != nil {
@@ -150,6 +162,7 @@ func (r *Relayer) initializeBTCCache(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failedt bitcoin node tip height: %w", err)
 	}
+	metrics.SetBTCTip(btcTipHeight)
 
 	r.logger.Info().
 		Int64("light_client", lcHeight).
@@ -210,6 +223,8 @@ func (r *Relayer) waitForBitcoinCatchup(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		metrics.SetBTCTip(btcLatestBlockHeight)
+		metrics.SetLCTip(lcLatestBlockHeight)
 
 		if isBTCCaughtUp(btcLatestBlockHeight, lcLatestBlockHeight) {
 			r.logger.Info().Msgf(
@@ -269,9 +284,14 @@ func (r *Relayer) backfillIndexer(ctx context.Context, startHeight, endHeight in
 		}
 
 		if len(blocksInBatch) > 0 {
-			if err := r.btcIndexer.SendBlocks(ctx, blocksInBatch); err != nil {
+			timer := prometheus.NewTimer(metrics.IndexerUploadDuration)
+			err := r.btcIndexer.SendBlocks(ctx, blocksInBatch)
+			timer.ObserveDuration()
+			if err != nil {
 				return fmt.Errorf("failed to send block batch to indexer: %w", err)
 			}
+			last := blocksInBatch[len(blocksInBatch)-1]
+			r.saveCheckpoint(ctx, store.CheckpointIndexer, last.BlockHeight, last.BlockHash())
 		}
 	}
 	r.logger.Info().Msg("Indexer backfill completed successfully.")
@@ -3,6 +3,7 @@ package bitcoinspv
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -57,7 +58,9 @@ func TestBootstrapRelayer(t *testing.T) {
 			}
 		}
 		btcClient.On("GetBTCTailBlocksByHeight", int64(latestFinalized), false).Return(blocks, nil)
-		lcClient.On("ContainsBlock", mock.Anything, mock.Anything).Return(true, nil)
+		lcClient.On("ContainsBlocks", mock.Anything, mock.Anything).Return(func(_ context.Context, hashes []chainhash.Hash) ([]bool, error) {
+			return slices.Repeat([]bool{true}, len(hashes)), nil
+		})
 		btcClient.On("SubscribeNewBlocks").Return()
 
 		err := r.bootstrapRelayer(ctx, false)
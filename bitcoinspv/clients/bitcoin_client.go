@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"errors"
+
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 
@@ -9,8 +11,15 @@ import (
 	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
 )
 
-// BTCClient is an abstraction over bitcoin node implementations (bitcoind, btcd).
-// Refer to btcwrapper/ dir for implementation.
+// ErrTxNotFound is returned when a transaction is unknown to the node or not confirmed yet.
+var ErrTxNotFound = errors.New("transaction not found or not confirmed")
+
+// ErrBlockNotFound is returned when a block hash is unknown to the node. The lookup isn't
+// retried.
+var ErrBlockNotFound = errors.New("block not found")
+
+// BTCClient is an abstraction over bitcoin node implementations (bitcoind, btcd) and
+// Esplora APIs. Refer to btcwrapper/ and esplora/ dirs for implementations.
 type BTCClient interface {
 	Stop()
 	WaitForShutdown()
@@ -21,4 +30,6 @@ type BTCClient interface {
 	GetBTCTailBlocksByHeight(height int64, fullBlocks bool) ([]*types.IndexedBlock, error)
 	GetBTCBlockByHeight(height int64) (*types.IndexedBlock, error)
 	GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, error)
+	// GetBTCTxBlockHash returns the hash of the block including the transaction.
+	GetBTCTxBlockHash(txID *chainhash.Hash) (*chainhash.Hash, error)
 }
@@ -2,15 +2,19 @@ package clients
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
 )
 
 // BlockInfo represents a simplified Bitcoin block with containing only essential information.
 type BlockInfo struct {
 	Hash   *chainhash.Hash
 	Height int64
+	// ChainWork is the cumulative work of the chain ending at the block.
+	ChainWork *big.Int
 }
 
 // BitcoinSPV defines the interface for interacting
@@ -19,12 +23,17 @@ type BlockInfo struct {
 type BitcoinSPV interface {
 
 	// InsertHeaders adds new Bitcoin block headers to the light client's chain.
-	InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) error
+	// Returns the digest of the transaction that inserted the headers.
+	InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) (string, error)
 
 	// GetLatestBlockInfo returns the block hash and height of the best (highest height)
 	// block header known to the light client.
 	GetLatestBlockInfo(ctx context.Context) (*BlockInfo, error)
 
+	// GetBlockInfo returns the block hash, height and chain work of a block header
+	// known to the light client.
+	GetBlockInfo(ctx context.Context, blockHash chainhash.Hash) (*BlockInfo, error)
+
 	// ContainsBlock checks if the light client's chain includes a block with the given hash.
 	//
 	// Returns:
@@ -33,6 +42,14 @@ type BitcoinSPV interface {
 	//   - (false, error) if there's an error during the check
 	ContainsBlock(ctx context.Context, blockHash chainhash.Hash) (bool, error)
 
+	// ContainsBlocks checks which of the given block hashes are included in the light
+	// client's chain. The result has the same order as the hashes.
+	ContainsBlocks(ctx context.Context, blockHashes []chainhash.Hash) ([]bool, error)
+
+	// VerifyTx checks with the light client that the merkle proof proves the inclusion of the
+	// transaction in the block at the given height of the light client's chain.
+	VerifyTx(ctx context.Context, height int64, proof *types.MerkleProof) (bool, error)
+
 	// Stop gracefully shuts down the SPV light client, releasing any resources.
 	Stop()
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/poller"
 	zmqclient "github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/zmq"
 	relayerconfig "github.com/gonative-cc/relayer/bitcoinspv/config"
 	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
@@ -71,6 +72,8 @@ func setupBackendConnection(client *Client) error {
 		return setupBitcoindConnection(client)
 	case btctypes.Btcd:
 		return setupBtcdConnection(client)
+	case btctypes.Polling:
+		return setupPollingConnection(client)
 	default:
 		return fmt.Errorf("unsupported backend type: %v", client.config.BtcBackend)
 	}
@@ -109,6 +112,34 @@ func setupBitcoindConnection(client *Client) error {
 	return nil
 }
 
+// setupPollingConnection connects to a node exposing only the JSON-RPC API. Block events
+// are derived by polling the best block hash.
+func setupPollingConnection(client *Client) error {
+	connectionCfg := &rpcclient.ConnConfig{
+		Host:         client.config.Endpoint,
+		HTTPPostMode: true,
+		User:         client.config.Username,
+		Pass:         client.config.Password,
+		DisableTLS:   client.config.DisableClientTLS,
+	}
+
+	rpcClient, err := rpcclient.New(connectionCfg, nil)
+	if err != nil {
+		return err
+	}
+	client.Client = rpcClient
+
+	pollerClient, err := poller.New(
+		client.logger, client.config.PollInterval, client.blockEventsChannel, rpcClient,
+	)
+	if err != nil {
+		return err
+	}
+	client.pollerClient = pollerClient
+
+	return nil
+}
+
 func setupBtcdConnection(client *Client) error {
 	notificationHandlers := rpcclient.NotificationHandlers{
 		OnFilteredBlockConnected: func(height int32, header *wire.BlockHeader, _ []*btcutil.Tx) {
@@ -176,6 +207,11 @@ func (client *Client) SubscribeNewBlocks() {
 		if err != nil {
 			panic(err)
 		}
+	case btctypes.Polling:
+		if err := bitcoinspv.RetryDo(client.logger, client.retrySleepDuration, client.maxRetrySleepDuration,
+			client.pollerClient.Start); err != nil {
+			panic(err)
+		}
 	}
 }
 
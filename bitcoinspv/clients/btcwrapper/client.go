@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/poller"
 	zeromq "github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/zmq"
 	relayerconfig "github.com/gonative-cc/relayer/bitcoinspv/config"
 	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
@@ -20,6 +21,7 @@ var _ clients.BTCClient = &Client{}
 type Client struct {
 	*rpcclient.Client
 	zeromqClient          *zeromq.Client
+	pollerClient          *poller.Client
 	chainParams           *chaincfg.Params
 	config                *relayerconfig.BTCConfig
 	logger                zerolog.Logger
@@ -31,6 +33,10 @@ type Client struct {
 // Stop gracefully shuts down the client and closes channels
 func (client *Client) Stop() {
 	if client != nil {
+		if client.pollerClient != nil {
+			// stop polling before closing the events channel
+			client.pollerClient.Stop()
+		}
 		client.Shutdown()
 		if client.blockEventsChannel != nil {
 			close(client.blockEventsChannel)
@@ -0,0 +1,237 @@
+// Package poller derives block events from a Bitcoin node exposing only the JSON-RPC API,
+// without ZMQ or websocket notifications. It polls the best block hash and, when it changes,
+// walks back to the fork point with the previously seen chain.
+package poller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+	"github.com/rs/zerolog"
+)
+
+// maxTrackedBlocks is the number of recent blocks of the best chain kept to find the fork
+// point of a new tip. It bounds the reorg depth the poller can follow block by block.
+const maxTrackedBlocks = 100
+
+var errClientStopped = errors.New("poller stopped")
+
+// NodeRPC is the subset of the Bitcoin node JSON-RPC API used by the poller.
+// It is implemented by rpcclient.Client.
+type NodeRPC interface {
+	GetBestBlockHash() (*chainhash.Hash, error)
+	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
+	GetBlockHeaderVerbose(blockHash *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error)
+}
+
+// trackedBlock is a block of the best chain known to the poller.
+type trackedBlock struct {
+	hash   chainhash.Hash
+	height int64
+	header *wire.BlockHeader
+}
+
+// Client polls the Bitcoin node and sends BlockConnected and BlockDisconnected events to
+// the block events channel, in the same order as the ZMQ sequence notifications.
+type Client struct {
+	rpc                NodeRPC
+	interval           time.Duration
+	logger             zerolog.Logger
+	blockEventsChannel chan *btctypes.BlockEvent
+
+	// blocks of the best chain, ordered by height
+	chain []trackedBlock
+
+	started  bool
+	stopOnce sync.Once
+	quitChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates a new poller client. Polling starts with Start.
+func New(
+	parentLogger zerolog.Logger,
+	interval time.Duration,
+	blockEventsChannel chan *btctypes.BlockEvent,
+	rpc NodeRPC,
+) (*Client, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive, got %v", interval)
+	}
+	return &Client{
+		rpc:                rpc,
+		interval:           interval,
+		logger:             parentLogger.With().Str("module", "poller").Logger(),
+		blockEventsChannel: blockEventsChannel,
+		quitChan:           make(chan struct{}),
+	}, nil
+}
+
+// Start records the current best block and starts polling for new blocks.
+// It can be retried when it fails. Calling Start after a success is a no-op.
+func (c *Client) Start() error {
+	if c.started {
+		return nil
+	}
+	if err := c.init(); err != nil {
+		return err
+	}
+	c.started = true
+	c.wg.Add(1)
+	go c.run()
+	c.logger.Info().Dur("interval", c.interval).Msg("Polling the Bitcoin node for new blocks")
+	return nil
+}
+
+// Stop stops polling and waits for the polling goroutine to exit.
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.quitChan)
+	})
+	c.wg.Wait()
+}
+
+func (c *Client) run() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quitChan:
+			return
+		case <-ticker.C:
+			if err := c.poll(); err != nil {
+				if errors.Is(err, errClientStopped) {
+					return
+				}
+				c.logger.Warn().Err(err).Msg("Failed to poll the Bitcoin node")
+			}
+		}
+	}
+}
+
+func (c *Client) init() error {
+	tip, err := c.best()
+	if err != nil {
+		return err
+	}
+	c.chain = []trackedBlock{*tip}
+	return nil
+}
+
+// best returns the best block of the node.
+func (c *Client) best() (*trackedBlock, error) {
+	hash, err := c.rpc.GetBestBlockHash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get best block hash: %w", err)
+	}
+	return c.block(hash)
+}
+
+func (c *Client) block(hash *chainhash.Hash) (*trackedBlock, error) {
+	verbose, err := c.rpc.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header %s: %w", hash, err)
+	}
+	header, err := c.rpc.GetBlockHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header %s: %w", hash, err)
+	}
+	return &trackedBlock{hash: *hash, height: int64(verbose.Height), header: header}, nil
+}
+
+// poll checks the best block of the node and sends the events moving the tracked chain
+// to the new best chain.
+func (c *Client) poll() error {
+	tip, err := c.best()
+	if err != nil {
+		return err
+	}
+	last := c.chain[len(c.chain)-1]
+	if tip.hash == last.hash {
+		return nil
+	}
+
+	forkIdx, branch, err := c.walkBack(tip)
+	if err != nil {
+		return err
+	}
+
+	if forkIdx < 0 {
+		// the fork point is older than the tracked blocks: start tracking from the new
+		// tip, the relayer reconciles its cache from the connected block
+		c.logger.Warn().Int64("height", tip.height).Str("hash", tip.hash.String()).
+			Msg("New best block doesn't share any tracked block, restarting tracking")
+		c.chain = []trackedBlock{*tip}
+		return c.send(btctypes.NewBlockEvent(btctypes.BlockConnected, tip.height, tip.header))
+	}
+
+	// disconnect blocks above the fork point, from the tip
+	for i := len(c.chain) - 1; i > forkIdx; i-- {
+		b := c.chain[i]
+		if err := c.send(btctypes.NewBlockEvent(btctypes.BlockDisconnected, b.height, b.header)); err != nil {
+			return err
+		}
+		c.chain = c.chain[:i]
+	}
+	// connect the new branch
+	for _, b := range branch {
+		if err := c.send(btctypes.NewBlockEvent(btctypes.BlockConnected, b.height, b.header)); err != nil {
+			return err
+		}
+		c.chain = append(c.chain, b)
+	}
+	if len(c.chain) > maxTrackedBlocks {
+		c.chain = slices.Clone(c.chain[len(c.chain)-maxTrackedBlocks:])
+	}
+	return nil
+}
+
+// walkBack follows the parents of tip until a tracked block. Returns the index of the
+// fork point in the tracked chain (-1 when not found within maxTrackedBlocks) and the
+// new branch above it, ordered by height.
+func (c *Client) walkBack(tip *trackedBlock) (int, []trackedBlock, error) {
+	branch := []trackedBlock{*tip}
+	cur := tip
+	first := c.chain[0]
+	for cur.height > first.height && len(branch) <= maxTrackedBlocks {
+		prevHash := cur.header.PrevBlock
+		if idx := c.indexOf(prevHash, cur.height-1); idx >= 0 {
+			slices.Reverse(branch)
+			return idx, branch, nil
+		}
+		prev, err := c.block(&prevHash)
+		if err != nil {
+			return -1, nil, err
+		}
+		branch = append(branch, *prev)
+		cur = prev
+	}
+	return -1, nil, nil
+}
+
+// indexOf returns the index of the tracked block with the given hash and height, or -1.
+func (c *Client) indexOf(hash chainhash.Hash, height int64) int {
+	idx := height - c.chain[0].height
+	if idx < 0 || idx >= int64(len(c.chain)) || c.chain[idx].hash != hash {
+		return -1
+	}
+	return int(idx)
+}
+
+func (c *Client) send(event *btctypes.BlockEvent) error {
+	select {
+	case c.blockEventsChannel <- event:
+		return nil
+	case <-c.quitChan:
+		return errClientStopped
+	}
+}
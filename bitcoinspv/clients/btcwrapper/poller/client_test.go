@@ -0,0 +1,178 @@
+package poller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+// fakeNode is a NodeRPC serving headers from memory.
+type fakeNode struct {
+	best    chainhash.Hash
+	headers map[chainhash.Hash]wire.BlockHeader
+	heights map[chainhash.Hash]int64
+	err     error
+}
+
+func newFakeNode() *fakeNode {
+	return &fakeNode{
+		headers: map[chainhash.Hash]wire.BlockHeader{},
+		heights: map[chainhash.Hash]int64{},
+	}
+}
+
+// add adds headers starting at the given height and sets the last one as the best block.
+func (n *fakeNode) add(height int64, headers []wire.BlockHeader) {
+	for i, h := range headers {
+		hash := h.BlockHash()
+		n.headers[hash] = h
+		n.heights[hash] = height + int64(i)
+		n.best = hash
+	}
+}
+
+func (n *fakeNode) GetBestBlockHash() (*chainhash.Hash, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	best := n.best
+	return &best, nil
+}
+
+func (n *fakeNode) GetBlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	h, ok := n.headers[*hash]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+	return &h, nil
+}
+
+func (n *fakeNode) GetBlockHeaderVerbose(hash *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	height, ok := n.heights[*hash]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+	return &btcjson.GetBlockHeaderVerboseResult{Hash: hash.String(), Height: int32(height)}, nil
+}
+
+type event struct {
+	kind   btctypes.EventType
+	height int64
+	hash   chainhash.Hash
+}
+
+func drain(ch chan *btctypes.BlockEvent) []event {
+	var events []event
+	for {
+		select {
+		case e := <-ch:
+			events = append(events, event{e.Type, e.Height, e.BlockHeader.BlockHash()})
+		default:
+			return events
+		}
+	}
+}
+
+// setupPollerTest returns a started poller tracking a node with 5 blocks (heights 100...104).
+func setupPollerTest(t *testing.T) (*Client, *fakeNode, chan *btctypes.BlockEvent, []wire.BlockHeader) {
+	t.Helper()
+	headers := btctypes.MineTestChain(t, chaincfg.RegressionNetParams.GenesisBlock.Header, 5)
+	node := newFakeNode()
+	node.add(100, headers)
+
+	ch := make(chan *btctypes.BlockEvent, 100)
+	c, err := New(zerolog.Nop(), time.Hour, ch, node)
+	assert.NoError(t, err)
+	assert.NoError(t, c.init())
+	assert.NoError(t, c.poll())
+	assert.Empty(t, drain(ch), "first poll only records the tip")
+	return c, node, ch, headers
+}
+
+func TestPoll(t *testing.T) {
+	t.Run("new blocks", func(t *testing.T) {
+		c, node, ch, headers := setupPollerTest(t)
+		next := btctypes.MineTestChain(t, headers[4], 2)
+		node.add(105, next)
+
+		assert.NoError(t, c.poll())
+		assert.Equal(t, []event{
+			{btctypes.BlockConnected, 105, next[0].BlockHash()},
+			{btctypes.BlockConnected, 106, next[1].BlockHash()},
+		}, drain(ch))
+
+		// nothing changed
+		assert.NoError(t, c.poll())
+		assert.Empty(t, drain(ch))
+	})
+
+	t.Run("reorg", func(t *testing.T) {
+		c, node, ch, headers := setupPollerTest(t)
+		next := btctypes.MineTestChain(t, headers[4], 1)
+		node.add(105, next)
+		assert.NoError(t, c.poll())
+		drain(ch)
+
+		// fork after 104 replacing 105 with 105', 106'
+		first := btctypes.MineTestHeader(t, &headers[4], headers[4].Timestamp.Add(10*time.Minute+time.Second),
+			chaincfg.RegressionNetParams.PowLimitBits)
+		fork := append([]wire.BlockHeader{first}, btctypes.MineTestChain(t, first, 1)...)
+		node.add(105, fork)
+
+		assert.NoError(t, c.poll())
+		assert.Equal(t, []event{
+			{btctypes.BlockDisconnected, 105, next[0].BlockHash()},
+			{btctypes.BlockConnected, 105, fork[0].BlockHash()},
+			{btctypes.BlockConnected, 106, fork[1].BlockHash()},
+		}, drain(ch))
+		assert.Len(t, c.chain, 3)
+	})
+
+	t.Run("fork point older than tracked blocks", func(t *testing.T) {
+		c, node, ch, _ := setupPollerTest(t)
+		// new chain from genesis, all blocks unknown to the poller
+		other := btctypes.MineTestChain(t, btctypes.MineTestHeader(t,
+			&chaincfg.RegressionNetParams.GenesisBlock.Header,
+			chaincfg.RegressionNetParams.GenesisBlock.Header.Timestamp.Add(time.Second),
+			chaincfg.RegressionNetParams.PowLimitBits), 6)
+		node.add(101, other)
+
+		assert.NoError(t, c.poll())
+		assert.Equal(t, []event{{btctypes.BlockConnected, 106, other[5].BlockHash()}}, drain(ch))
+		assert.Len(t, c.chain, 1)
+	})
+
+	t.Run("node error", func(t *testing.T) {
+		c, node, ch, _ := setupPollerTest(t)
+		node.err = errors.New("connection refused")
+		assert.Error(t, c.poll())
+		assert.Empty(t, drain(ch))
+	})
+}
+
+func TestStartRetry(t *testing.T) {
+	node := newFakeNode()
+	node.err = errors.New("connection refused")
+	c, err := New(zerolog.Nop(), time.Hour, make(chan *btctypes.BlockEvent), node)
+	assert.NoError(t, err)
+	assert.Error(t, c.Start())
+
+	node.err = nil
+	node.add(100, btctypes.MineTestChain(t, chaincfg.RegressionNetParams.GenesisBlock.Header, 1))
+	assert.NoError(t, c.Start())
+	c.Stop()
+}
+
+func TestNewInvalidInterval(t *testing.T) {
+	_, err := New(zerolog.Nop(), 0, nil, newFakeNode())
+	assert.Error(t, err)
+}
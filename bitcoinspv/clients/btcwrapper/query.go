@@ -1,6 +1,7 @@
 package btcwrapper
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/btcjson"
@@ -143,7 +144,7 @@ func (c *Client) getBlockRetries(hash *chainhash.Hash) (*wire.MsgBlock, error) {
 	if err := bitcoinspv.RetryDo(c.logger, c.retrySleepDuration, c.maxRetrySleepDuration, func() error {
 		var err error
 		block, err = c.GetBlock(hash)
-		return err
+		return blockNotFound(hash, err)
 	}); err != nil {
 		return nil, err
 	}
@@ -159,7 +160,7 @@ func (c *Client) getBlockVerboseRetries(
 	if err := bitcoinspv.RetryDo(c.logger, c.retrySleepDuration, c.maxRetrySleepDuration, func() error {
 		var err error
 		blockVerbose, err = c.GetBlockVerbose(hash)
-		return err
+		return blockNotFound(hash, err)
 	}); err != nil {
 		return nil, err
 	}
@@ -167,6 +168,16 @@ func (c *Client) getBlockVerboseRetries(
 	return blockVerbose, nil
 }
 
+// blockNotFound reports the node error of an unknown block as clients.ErrBlockNotFound, so
+// the lookup isn't retried.
+func blockNotFound(hash *chainhash.Hash, err error) error {
+	var rpcErr *btcjson.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCBlockNotFound {
+		return fmt.Errorf("%w: %s: %s", clients.ErrBlockNotFound, hash, rpcErr.Message)
+	}
+	return err
+}
+
 // GetBTCTailBlocksByHeight retrieves a sequence of blocks or block headers
 // from a given base height up to the current chain tip, based on the fullBlocks flag.
 func (c *Client) GetBTCTailBlocksByHeight(
@@ -234,3 +245,20 @@ func (c *Client) GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, err
 
 	return header, nil
 }
+
+// GetBTCTxBlockHash returns the hash of the block including the transaction. Requires the
+// node transaction index (`txindex=1`) for transactions not in the wallet.
+func (c *Client) GetBTCTxBlockHash(txID *chainhash.Hash) (*chainhash.Hash, error) {
+	tx, err := c.GetRawTransactionVerbose(txID)
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCNoTxInfo {
+			return nil, fmt.Errorf("%w: %s: %s", clients.ErrTxNotFound, txID, rpcErr.Message)
+		}
+		return nil, fmt.Errorf("failed to get transaction %s: %w", txID, err)
+	}
+	if tx.BlockHash == "" {
+		return nil, fmt.Errorf("%w: %s", clients.ErrTxNotFound, txID)
+	}
+	return chainhash.NewHashFromStr(tx.BlockHash)
+}
@@ -0,0 +1,188 @@
+// Package esplora implements the BTCClient over the Esplora HTTP API
+// (https://github.com/Blockstream/esplora/blob/master/API.md), so the relayer can run without
+// a Bitcoin full node. New blocks are detected by polling the chain tip.
+package esplora
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/rs/zerolog"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/poller"
+	relayerconfig "github.com/gonative-cc/relayer/bitcoinspv/config"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+var _ clients.BTCClient = (*Client)(nil)
+
+const (
+	// requestTimeout bounds a single request to the Esplora API
+	requestTimeout = 30 * time.Second
+	// maxErrorBody is the number of bytes of an error response included in the error
+	maxErrorBody = 512
+)
+
+// Client queries an Esplora compatible HTTP API.
+type Client struct {
+	baseURL               string
+	httpClient            *http.Client
+	logger                zerolog.Logger
+	poller                *poller.Client
+	blockEventsChannel    chan *btctypes.BlockEvent
+	retrySleepDuration    time.Duration
+	maxRetrySleepDuration time.Duration
+
+	stopOnce sync.Once
+	quit     chan struct{}
+}
+
+// New creates a new Esplora client. The BTCConfig endpoint is the base URL of the API,
+// e.g. https://blockstream.info/api.
+func New(
+	config *relayerconfig.BTCConfig,
+	retrySleepDuration,
+	maxRetrySleepDuration time.Duration,
+	parentLogger zerolog.Logger,
+) (*Client, error) {
+	return newClient(config.Endpoint, config.PollInterval, &http.Client{Timeout: requestTimeout},
+		retrySleepDuration, maxRetrySleepDuration, parentLogger)
+}
+
+func newClient(
+	endpoint string,
+	pollInterval time.Duration,
+	httpClient *http.Client,
+	retrySleepDuration,
+	maxRetrySleepDuration time.Duration,
+	parentLogger zerolog.Logger,
+) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid esplora endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("esplora endpoint must be an http(s) URL, got %q", endpoint)
+	}
+
+	c := &Client{
+		baseURL:               strings.TrimSuffix(u.String(), "/"),
+		httpClient:            httpClient,
+		logger:                parentLogger.With().Str("module", "esplora").Logger(),
+		blockEventsChannel:    make(chan *btctypes.BlockEvent, 10000),
+		retrySleepDuration:    retrySleepDuration,
+		maxRetrySleepDuration: maxRetrySleepDuration,
+		quit:                  make(chan struct{}),
+	}
+	c.poller, err = poller.New(c.logger, pollInterval, c.blockEventsChannel, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SubscribeNewBlocks starts polling the chain tip for new blocks
+func (c *Client) SubscribeNewBlocks() {
+	if err := bitcoinspv.RetryDo(c.logger, c.retrySleepDuration, c.maxRetrySleepDuration,
+		c.poller.Start); err != nil {
+		panic(err)
+	}
+}
+
+// BlockEventChannel returns the channel used for block events
+func (c *Client) BlockEventChannel() <-chan *btctypes.BlockEvent {
+	return c.blockEventsChannel
+}
+
+// Stop stops polling and closes the block events channel
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() {
+		c.poller.Stop()
+		close(c.blockEventsChannel)
+		c.httpClient.CloseIdleConnections()
+		close(c.quit)
+	})
+}
+
+// WaitForShutdown blocks until the client is stopped
+func (c *Client) WaitForShutdown() {
+	<-c.quit
+}
+
+// get requests the given API path and returns the response body.
+func (c *Client) get(path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &statusError{path: path, code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// statusError is returned by get when the API answers with an error status.
+type statusError struct {
+	path string
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("esplora GET %s returned status %d: %s", e.path, e.code, e.body)
+}
+
+// getRetries calls get with the relayer retry policy.
+func (c *Client) getRetries(path string) ([]byte, error) {
+	var body []byte
+	err := bitcoinspv.RetryDo(c.logger, c.retrySleepDuration, c.maxRetrySleepDuration, func() error {
+		var err error
+		body, err = c.get(path)
+		return err
+	})
+	return body, err
+}
+
+// getBlockRetries calls get for a path of the given block with the relayer retry policy. A
+// 404 status is reported as clients.ErrBlockNotFound, and isn't retried.
+func (c *Client) getBlockRetries(blockHash *chainhash.Hash, path string) ([]byte, error) {
+	var body []byte
+	err := bitcoinspv.RetryDo(c.logger, c.retrySleepDuration, c.maxRetrySleepDuration, func() error {
+		var err error
+		body, err = c.get(path)
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+			return fmt.Errorf("%w: %s: %w", clients.ErrBlockNotFound, blockHash, err)
+		}
+		return err
+	})
+	return body, err
+}
+
+// getText requests a plain text API path and returns the trimmed response.
+func (c *Client) getText(path string) (string, error) {
+	body, err := c.getRetries(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,205 @@
+package esplora
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+const esploraTestFirstHeight = 100
+
+// fakeEsplora serves the Esplora endpoints used by the client for a chain of headers
+// starting at esploraTestFirstHeight.
+type fakeEsplora struct {
+	mu      sync.Mutex
+	headers []wire.BlockHeader
+}
+
+func (f *fakeEsplora) tip() wire.BlockHeader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.headers[len(f.headers)-1]
+}
+
+func (f *fakeEsplora) add(headers []wire.BlockHeader) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers = append(f.headers, headers...)
+}
+
+func (f *fakeEsplora) find(hash string) (int, bool) {
+	for i := range f.headers {
+		if f.headers[i].BlockHash().String() == hash {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (f *fakeEsplora) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tip := len(f.headers) - 1
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/blocks/tip/height":
+		fmt.Fprint(w, esploraTestFirstHeight+tip)
+	case r.URL.Path == "/blocks/tip/hash":
+		fmt.Fprint(w, f.headers[tip].BlockHash())
+	case len(parts) == 2 && parts[0] == "block-height":
+		h, err := strconv.Atoi(parts[1])
+		if err != nil || h < esploraTestFirstHeight || h > esploraTestFirstHeight+tip {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, f.headers[h-esploraTestFirstHeight].BlockHash())
+	case len(parts) >= 2 && parts[0] == "block":
+		i, ok := f.find(parts[1])
+		if !ok {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return
+		}
+		var buf bytes.Buffer
+		switch {
+		case len(parts) == 2:
+			fmt.Fprintf(w, `{"id":"%s","height":%d}`, parts[1], esploraTestFirstHeight+i)
+		case parts[2] == "header":
+			_ = f.headers[i].Serialize(&buf)
+			fmt.Fprint(w, hex.EncodeToString(buf.Bytes()))
+		case parts[2] == "raw":
+			_ = wire.NewMsgBlock(&f.headers[i]).Serialize(&buf)
+			_, _ = w.Write(buf.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	case len(parts) == 3 && parts[0] == "tx" && parts[2] == "status":
+		// the transactions are identified by the hash of their block, the zero hash is an
+		// unconfirmed transaction
+		if parts[1] == (chainhash.Hash{}).String() {
+			fmt.Fprint(w, `{"confirmed":false}`)
+			return
+		}
+		if parts[1] == (chainhash.Hash{2}).String() {
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		for i := range f.headers {
+			if f.headers[i].BlockHash().String() == parts[1] {
+				fmt.Fprintf(w, `{"confirmed":true,"block_hash":"%s"}`, f.headers[i].BlockHash())
+				return
+			}
+		}
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func setupEsploraTest(t *testing.T, pollInterval time.Duration) (*Client, *fakeEsplora) {
+	t.Helper()
+	f := &fakeEsplora{headers: btctypes.MineTestChain(t, chaincfg.RegressionNetParams.GenesisBlock.Header, 3)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	c, err := newClient(srv.URL+"/", pollInterval, srv.Client(), time.Millisecond, 5*time.Millisecond, zerolog.Nop())
+	require.NoError(t, err)
+	return c, f
+}
+
+func TestQuery(t *testing.T) {
+	c, f := setupEsploraTest(t, time.Hour)
+
+	hash, height, err := c.GetBTCTipBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), height)
+	assert.Equal(t, f.headers[2].BlockHash(), *hash)
+
+	header, err := c.GetBTCBlockHeaderByHeight(101)
+	assert.NoError(t, err)
+	assert.Equal(t, f.headers[1], *header)
+
+	block, err := c.GetBTCBlockByHeight(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), block.BlockHeight)
+	assert.Equal(t, f.headers[0].BlockHash(), block.BlockHash())
+
+	block, err = c.GetBTCBlockByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), block.BlockHeight)
+
+	_, err = c.GetBTCBlockByHash(&chainhash.Hash{1})
+	assert.ErrorIs(t, err, clients.ErrBlockNotFound)
+	assert.NotErrorIs(t, err, bitcoinspv.ErrRetryTimeout)
+
+	blocks, err := c.GetBTCTailBlocksByHeight(101, false)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, f.headers[2].BlockHash(), blocks[1].BlockHash())
+
+	_, err = c.GetBTCTailBlocksByHeight(103, true)
+	assert.Error(t, err)
+
+	_, err = c.GetBTCBlockByHeight(200)
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestGetBTCTxBlockHash(t *testing.T) {
+	c, f := setupEsploraTest(t, time.Hour)
+
+	txID := f.headers[1].BlockHash()
+	hash, err := c.GetBTCTxBlockHash(&txID)
+	require.NoError(t, err)
+	assert.Equal(t, f.headers[1].BlockHash(), *hash)
+
+	_, err = c.GetBTCTxBlockHash(&chainhash.Hash{})
+	assert.ErrorIs(t, err, clients.ErrTxNotFound)
+
+	_, err = c.GetBTCTxBlockHash(&chainhash.Hash{1})
+	assert.ErrorIs(t, err, clients.ErrTxNotFound)
+
+	// backend errors are not reported as unknown transactions
+	_, err = c.GetBTCTxBlockHash(&chainhash.Hash{2})
+	assert.ErrorContains(t, err, "status 503")
+	assert.NotErrorIs(t, err, clients.ErrTxNotFound)
+}
+
+func TestNewBlockEvents(t *testing.T) {
+	c, f := setupEsploraTest(t, 10*time.Millisecond)
+	c.SubscribeNewBlocks()
+	defer c.Stop()
+
+	next := btctypes.MineTestChain(t, f.tip(), 1)
+	f.add(next)
+
+	select {
+	case e := <-c.BlockEventChannel():
+		assert.Equal(t, btctypes.BlockConnected, e.Type)
+		assert.Equal(t, int64(103), e.Height)
+		assert.Equal(t, next[0].BlockHash(), e.BlockHeader.BlockHash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the block event")
+	}
+}
+
+func TestNewInvalidEndpoint(t *testing.T) {
+	_, err := newClient("localhost:3000", time.Second, http.DefaultClient, time.Millisecond, time.Millisecond, zerolog.Nop())
+	assert.Error(t, err)
+}
@@ -0,0 +1,211 @@
+package esplora
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper/poller"
+	relayertypes "github.com/gonative-cc/relayer/bitcoinspv/types"
+)
+
+var _ poller.NodeRPC = (*Client)(nil)
+
+// blockStatus is the subset of the /block/:hash response used by the client
+type blockStatus struct {
+	ID     string `json:"id"`
+	Height int64  `json:"height"`
+}
+
+// txStatus is the /tx/:txid/status response
+type txStatus struct {
+	Confirmed bool   `json:"confirmed"`
+	BlockHash string `json:"block_hash"`
+}
+
+// GetBTCTipBlock returns the latest block hash and height
+func (c *Client) GetBTCTipBlock() (*chainhash.Hash, int64, error) {
+	heightStr, err := c.getText("/blocks/tip/height")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get tip height: %w", err)
+	}
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid tip height %q: %w", heightStr, err)
+	}
+	hash, err := c.getBlockHash(height)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hash, height, nil
+}
+
+// GetBTCBlockByHash returns the block of given block hash
+func (c *Client) GetBTCBlockByHash(blockHash *chainhash.Hash) (*relayertypes.IndexedBlock, error) {
+	status, err := c.getBlockStatus(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := c.getBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return relayertypes.NewIndexedBlock(status.Height, block), nil
+}
+
+// GetBTCBlockByHeight returns a block with the given height
+func (c *Client) GetBTCBlockByHeight(height int64) (*relayertypes.IndexedBlock, error) {
+	hash, err := c.getBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	block, err := c.getBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+	return relayertypes.NewIndexedBlock(height, block), nil
+}
+
+// GetBTCBlockHeaderByHeight retrieves only the block header for a given height.
+func (c *Client) GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, error) {
+	hash, err := c.getBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetBlockHeader(hash)
+}
+
+// GetBTCTailBlocksByHeight retrieves a sequence of blocks or block headers
+// from a given base height up to the current chain tip, based on the fullBlocks flag.
+func (c *Client) GetBTCTailBlocksByHeight(baseHeight int64, fullBlocks bool) ([]*relayertypes.IndexedBlock, error) {
+	_, tipHeight, err := c.GetBTCTipBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tip block: %w", err)
+	}
+	if baseHeight > tipHeight {
+		return nil, fmt.Errorf("base height %d exceeds current tip height %d", baseHeight, tipHeight)
+	}
+
+	blocks := make([]*relayertypes.IndexedBlock, 0, tipHeight-baseHeight+1)
+	for height := baseHeight; height <= tipHeight; height++ {
+		if fullBlocks {
+			block, err := c.GetBTCBlockByHeight(height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get block at height %d: %w", height, err)
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+		header, err := c.GetBTCBlockHeaderByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block header at height %d: %w", height, err)
+		}
+		blocks = append(blocks, relayertypes.NewIndexedBlock(height, wire.NewMsgBlock(header)))
+	}
+	c.logger.Info().Msgf("Successfully fetched %d blocks/headers.", len(blocks))
+	return blocks, nil
+}
+
+// GetBTCTxBlockHash returns the hash of the block including the transaction
+func (c *Client) GetBTCTxBlockHash(txID *chainhash.Hash) (*chainhash.Hash, error) {
+	// not retried: unknown transactions are reported with a 404 status
+	body, err := c.get("/tx/" + txID.String() + "/status")
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s: %w", clients.ErrTxNotFound, txID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tx status %s: %w", txID, err)
+	}
+	var status txStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("invalid tx status %s: %w", txID, err)
+	}
+	if !status.Confirmed {
+		return nil, fmt.Errorf("%w: %s", clients.ErrTxNotFound, txID)
+	}
+	return chainhash.NewHashFromStr(status.BlockHash)
+}
+
+// GetBestBlockHash returns the hash of the chain tip. Used by the poller.
+func (c *Client) GetBestBlockHash() (*chainhash.Hash, error) {
+	hashStr, err := c.getText("/blocks/tip/hash")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tip hash: %w", err)
+	}
+	return chainhash.NewHashFromStr(hashStr)
+}
+
+// GetBlockHeader returns the header of the given block. Used by the poller.
+func (c *Client) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	headerHex, err := c.getText("/block/" + blockHash.String() + "/header")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header %s: %w", blockHash, err)
+	}
+	raw, err := hex.DecodeString(headerHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block header %s: %w", blockHash, err)
+	}
+	var header wire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("invalid block header %s: %w", blockHash, err)
+	}
+	if header.BlockHash() != *blockHash {
+		return nil, fmt.Errorf("esplora returned header %s for block %s", header.BlockHash(), blockHash)
+	}
+	return &header, nil
+}
+
+// GetBlockHeaderVerbose returns the height of the given block. Only the hash and height
+// fields are set. Used by the poller.
+func (c *Client) GetBlockHeaderVerbose(blockHash *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	status, err := c.getBlockStatus(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &btcjson.GetBlockHeaderVerboseResult{Hash: status.ID, Height: int32(status.Height)}, nil
+}
+
+func (c *Client) getBlockHash(height int64) (*chainhash.Hash, error) {
+	hashStr, err := c.getText("/block-height/" + strconv.FormatInt(height, 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block hash for height %d: %w", height, err)
+	}
+	return chainhash.NewHashFromStr(hashStr)
+}
+
+func (c *Client) getBlockStatus(blockHash *chainhash.Hash) (*blockStatus, error) {
+	body, err := c.getBlockRetries(blockHash, "/block/"+blockHash.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", blockHash, err)
+	}
+	var status blockStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("invalid block %s: %w", blockHash, err)
+	}
+	return &status, nil
+}
+
+func (c *Client) getBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	raw, err := c.getBlockRetries(blockHash, "/block/"+blockHash.String()+"/raw")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", blockHash, err)
+	}
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("invalid block %s: %w", blockHash, err)
+	}
+	if block.BlockHash() != *blockHash {
+		return nil, fmt.Errorf("esplora returned block %s for block %s", block.BlockHash(), blockHash)
+	}
+	return &block, nil
+}
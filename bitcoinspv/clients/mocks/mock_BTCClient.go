@@ -370,6 +370,64 @@ func (_c *MockBTCClient_GetBTCTipBlock_Call) RunAndReturn(run func() (*chainhash
 	return _c
 }
 
+// GetBTCTxBlockHash provides a mock function with given fields: txID
+func (_m *MockBTCClient) GetBTCTxBlockHash(txID *chainhash.Hash) (*chainhash.Hash, error) {
+	ret := _m.Called(txID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBTCTxBlockHash")
+	}
+
+	var r0 *chainhash.Hash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*chainhash.Hash) (*chainhash.Hash, error)); ok {
+		return rf(txID)
+	}
+	if rf, ok := ret.Get(0).(func(*chainhash.Hash) *chainhash.Hash); ok {
+		r0 = rf(txID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*chainhash.Hash)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*chainhash.Hash) error); ok {
+		r1 = rf(txID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBTCClient_GetBTCTxBlockHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBTCTxBlockHash'
+type MockBTCClient_GetBTCTxBlockHash_Call struct {
+	*mock.Call
+}
+
+// GetBTCTxBlockHash is a helper method to define mock.On call
+//   - txID *chainhash.Hash
+func (_e *MockBTCClient_Expecter) GetBTCTxBlockHash(txID interface{}) *MockBTCClient_GetBTCTxBlockHash_Call {
+	return &MockBTCClient_GetBTCTxBlockHash_Call{Call: _e.mock.On("GetBTCTxBlockHash", txID)}
+}
+
+func (_c *MockBTCClient_GetBTCTxBlockHash_Call) Run(run func(txID *chainhash.Hash)) *MockBTCClient_GetBTCTxBlockHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*chainhash.Hash))
+	})
+	return _c
+}
+
+func (_c *MockBTCClient_GetBTCTxBlockHash_Call) Return(_a0 *chainhash.Hash, _a1 error) *MockBTCClient_GetBTCTxBlockHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBTCClient_GetBTCTxBlockHash_Call) RunAndReturn(run func(*chainhash.Hash) (*chainhash.Hash, error)) *MockBTCClient_GetBTCTxBlockHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Stop provides a mock function with no fields
 func (_m *MockBTCClient) Stop() {
 	_m.Called()
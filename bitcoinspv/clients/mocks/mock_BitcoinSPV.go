@@ -10,6 +10,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	types "github.com/gonative-cc/relayer/bitcoinspv/types"
+
 	wire "github.com/btcsuite/btcd/wire"
 )
 
@@ -83,6 +85,124 @@ func (_c *MockBitcoinSPV_ContainsBlock_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// ContainsBlocks provides a mock function with given fields: ctx, blockHashes
+func (_m *MockBitcoinSPV) ContainsBlocks(ctx context.Context, blockHashes []chainhash.Hash) ([]bool, error) {
+	ret := _m.Called(ctx, blockHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContainsBlocks")
+	}
+
+	var r0 []bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []chainhash.Hash) ([]bool, error)); ok {
+		return rf(ctx, blockHashes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []chainhash.Hash) []bool); ok {
+		r0 = rf(ctx, blockHashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bool)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []chainhash.Hash) error); ok {
+		r1 = rf(ctx, blockHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBitcoinSPV_ContainsBlocks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ContainsBlocks'
+type MockBitcoinSPV_ContainsBlocks_Call struct {
+	*mock.Call
+}
+
+// ContainsBlocks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blockHashes []chainhash.Hash
+func (_e *MockBitcoinSPV_Expecter) ContainsBlocks(ctx interface{}, blockHashes interface{}) *MockBitcoinSPV_ContainsBlocks_Call {
+	return &MockBitcoinSPV_ContainsBlocks_Call{Call: _e.mock.On("ContainsBlocks", ctx, blockHashes)}
+}
+
+func (_c *MockBitcoinSPV_ContainsBlocks_Call) Run(run func(ctx context.Context, blockHashes []chainhash.Hash)) *MockBitcoinSPV_ContainsBlocks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]chainhash.Hash))
+	})
+	return _c
+}
+
+func (_c *MockBitcoinSPV_ContainsBlocks_Call) Return(_a0 []bool, _a1 error) *MockBitcoinSPV_ContainsBlocks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBitcoinSPV_ContainsBlocks_Call) RunAndReturn(run func(context.Context, []chainhash.Hash) ([]bool, error)) *MockBitcoinSPV_ContainsBlocks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBlockInfo provides a mock function with given fields: ctx, blockHash
+func (_m *MockBitcoinSPV) GetBlockInfo(ctx context.Context, blockHash chainhash.Hash) (*clients.BlockInfo, error) {
+	ret := _m.Called(ctx, blockHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockInfo")
+	}
+
+	var r0 *clients.BlockInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, chainhash.Hash) (*clients.BlockInfo, error)); ok {
+		return rf(ctx, blockHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, chainhash.Hash) *clients.BlockInfo); ok {
+		r0 = rf(ctx, blockHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*clients.BlockInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, chainhash.Hash) error); ok {
+		r1 = rf(ctx, blockHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBitcoinSPV_GetBlockInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBlockInfo'
+type MockBitcoinSPV_GetBlockInfo_Call struct {
+	*mock.Call
+}
+
+// GetBlockInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blockHash chainhash.Hash
+func (_e *MockBitcoinSPV_Expecter) GetBlockInfo(ctx interface{}, blockHash interface{}) *MockBitcoinSPV_GetBlockInfo_Call {
+	return &MockBitcoinSPV_GetBlockInfo_Call{Call: _e.mock.On("GetBlockInfo", ctx, blockHash)}
+}
+
+func (_c *MockBitcoinSPV_GetBlockInfo_Call) Run(run func(ctx context.Context, blockHash chainhash.Hash)) *MockBitcoinSPV_GetBlockInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(chainhash.Hash))
+	})
+	return _c
+}
+
+func (_c *MockBitcoinSPV_GetBlockInfo_Call) Return(_a0 *clients.BlockInfo, _a1 error) *MockBitcoinSPV_GetBlockInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBitcoinSPV_GetBlockInfo_Call) RunAndReturn(run func(context.Context, chainhash.Hash) (*clients.BlockInfo, error)) *MockBitcoinSPV_GetBlockInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetLatestBlockInfo provides a mock function with given fields: ctx
 func (_m *MockBitcoinSPV) GetLatestBlockInfo(ctx context.Context) (*clients.BlockInfo, error) {
 	ret := _m.Called(ctx)
@@ -142,21 +262,31 @@ func (_c *MockBitcoinSPV_GetLatestBlockInfo_Call) RunAndReturn(run func(context.
 }
 
 // InsertHeaders provides a mock function with given fields: ctx, blockHeaders
-func (_m *MockBitcoinSPV) InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) error {
+func (_m *MockBitcoinSPV) InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) (string, error) {
 	ret := _m.Called(ctx, blockHeaders)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InsertHeaders")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, []wire.BlockHeader) error); ok {
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []wire.BlockHeader) (string, error)); ok {
+		return rf(ctx, blockHeaders)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []wire.BlockHeader) string); ok {
 		r0 = rf(ctx, blockHeaders)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []wire.BlockHeader) error); ok {
+		r1 = rf(ctx, blockHeaders)
+	} else {
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
 // MockBitcoinSPV_InsertHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertHeaders'
@@ -178,12 +308,12 @@ func (_c *MockBitcoinSPV_InsertHeaders_Call) Run(run func(ctx context.Context, b
 	return _c
 }
 
-func (_c *MockBitcoinSPV_InsertHeaders_Call) Return(_a0 error) *MockBitcoinSPV_InsertHeaders_Call {
-	_c.Call.Return(_a0)
+func (_c *MockBitcoinSPV_InsertHeaders_Call) Return(_a0 string, _a1 error) *MockBitcoinSPV_InsertHeaders_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockBitcoinSPV_InsertHeaders_Call) RunAndReturn(run func(context.Context, []wire.BlockHeader) error) *MockBitcoinSPV_InsertHeaders_Call {
+func (_c *MockBitcoinSPV_InsertHeaders_Call) RunAndReturn(run func(context.Context, []wire.BlockHeader) (string, error)) *MockBitcoinSPV_InsertHeaders_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -220,6 +350,64 @@ func (_c *MockBitcoinSPV_Stop_Call) RunAndReturn(run func()) *MockBitcoinSPV_Sto
 	return _c
 }
 
+// VerifyTx provides a mock function with given fields: ctx, height, proof
+func (_m *MockBitcoinSPV) VerifyTx(ctx context.Context, height int64, proof *types.MerkleProof) (bool, error) {
+	ret := _m.Called(ctx, height, proof)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyTx")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.MerkleProof) (bool, error)); ok {
+		return rf(ctx, height, proof)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.MerkleProof) bool); ok {
+		r0 = rf(ctx, height, proof)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.MerkleProof) error); ok {
+		r1 = rf(ctx, height, proof)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBitcoinSPV_VerifyTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyTx'
+type MockBitcoinSPV_VerifyTx_Call struct {
+	*mock.Call
+}
+
+// VerifyTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - height int64
+//   - proof *types.MerkleProof
+func (_e *MockBitcoinSPV_Expecter) VerifyTx(ctx interface{}, height interface{}, proof interface{}) *MockBitcoinSPV_VerifyTx_Call {
+	return &MockBitcoinSPV_VerifyTx_Call{Call: _e.mock.On("VerifyTx", ctx, height, proof)}
+}
+
+func (_c *MockBitcoinSPV_VerifyTx_Call) Run(run func(ctx context.Context, height int64, proof *types.MerkleProof)) *MockBitcoinSPV_VerifyTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(*types.MerkleProof))
+	})
+	return _c
+}
+
+func (_c *MockBitcoinSPV_VerifyTx_Call) Return(_a0 bool, _a1 error) *MockBitcoinSPV_VerifyTx_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBitcoinSPV_VerifyTx_Call) RunAndReturn(run func(context.Context, int64, *types.MerkleProof) (bool, error)) *MockBitcoinSPV_VerifyTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockBitcoinSPV creates a new instance of MockBitcoinSPV. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockBitcoinSPV(t interface {
@@ -0,0 +1,160 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+var (
+	// errUnconnectedHeaders is returned when the first header doesn't extend any known header.
+	errUnconnectedHeaders = errors.New("headers don't connect to the known chain")
+	// errHeaderNotFound is returned for heights outside of the synced header chain.
+	errHeaderNotFound = errors.New("header not found")
+)
+
+// headerChain is the in-memory best header chain. It starts after a trusted base block (a
+// checkpoint of the network, or the genesis block), so headers below the base are unknown.
+// headerChain is not safe for concurrent use.
+type headerChain struct {
+	powLimit   *big.Int
+	timeSource blockchain.MedianTimeSource
+	baseHeight int64
+	baseHash   chainhash.Hash
+	// headers[i] is the header at height baseHeight+1+i
+	headers []wire.BlockHeader
+	index   map[chainhash.Hash]int64
+}
+
+// newHeaderChain creates a header chain based on the last checkpoint at or below
+// startHeight, or on the last checkpoint of the network when startHeight is 0.
+func newHeaderChain(params *chaincfg.Params, startHeight int64) *headerChain {
+	c := &headerChain{
+		powLimit:   params.PowLimit,
+		timeSource: blockchain.NewMedianTime(),
+		baseHash:   *params.GenesisHash,
+		index:      map[chainhash.Hash]int64{},
+	}
+	for _, cp := range params.Checkpoints {
+		if startHeight > 0 && int64(cp.Height) > startHeight {
+			break
+		}
+		c.baseHeight = int64(cp.Height)
+		c.baseHash = *cp.Hash
+	}
+	c.index[c.baseHash] = c.baseHeight
+	return c
+}
+
+// tip returns the hash and height of the last header.
+func (c *headerChain) tip() (chainhash.Hash, int64) {
+	if len(c.headers) == 0 {
+		return c.baseHash, c.baseHeight
+	}
+	return c.headers[len(c.headers)-1].BlockHash(), c.tipHeight()
+}
+
+func (c *headerChain) tipHeight() int64 {
+	return c.baseHeight + int64(len(c.headers))
+}
+
+// header returns the header at the given height.
+func (c *headerChain) header(height int64) (*wire.BlockHeader, error) {
+	if height <= c.baseHeight {
+		return nil, fmt.Errorf("%w: height %d is at or below the start of the headers sync %d, "+
+			"lower p2p-start-height to sync it", errHeaderNotFound, height, c.baseHeight)
+	}
+	if height > c.tipHeight() {
+		return nil, fmt.Errorf("%w: height %d, synced headers are in (%d, %d]",
+			errHeaderNotFound, height, c.baseHeight, c.tipHeight())
+	}
+	h := c.headers[height-c.baseHeight-1]
+	return &h, nil
+}
+
+// locator returns the block locator of the chain: the 10 last hashes, then exponentially
+// spaced hashes down to the base.
+func (c *headerChain) locator() blockchain.BlockLocator {
+	var locator blockchain.BlockLocator
+	step := int64(1)
+	for h := c.tipHeight(); h > c.baseHeight; h -= step {
+		hash := c.headers[h-c.baseHeight-1].BlockHash()
+		locator = append(locator, &hash)
+		if len(locator) >= 10 {
+			step *= 2
+		}
+	}
+	base := c.baseHash
+	return append(locator, &base)
+}
+
+// connect adds headers to the chain. Headers replacing a part of the chain are accepted only
+// when they have more work than the replaced headers. Returns the events moving the chain to
+// the new tip: disconnected blocks from the old tip first, then connected blocks by height.
+func (c *headerChain) connect(headers []wire.BlockHeader) ([]*btctypes.BlockEvent, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	parent, ok := c.index[headers[0].PrevBlock]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown parent %s", errUnconnectedHeaders, headers[0].PrevBlock)
+	}
+	if err := c.checkHeaders(headers); err != nil {
+		return nil, err
+	}
+
+	// skip headers we already have
+	for len(headers) > 0 && parent < c.tipHeight() &&
+		c.headers[parent-c.baseHeight].BlockHash() == headers[0].BlockHash() {
+		parent++
+		headers = headers[1:]
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	replaced := c.headers[parent-c.baseHeight:]
+	if len(replaced) > 0 &&
+		btctypes.HeadersWork(headers).Cmp(btctypes.HeadersWork(replaced)) <= 0 {
+		// the current chain has at least as much work, keep it
+		return nil, nil
+	}
+
+	events := make([]*btctypes.BlockEvent, 0, len(replaced)+len(headers))
+	for i := len(replaced) - 1; i >= 0; i-- {
+		h := replaced[i]
+		delete(c.index, h.BlockHash())
+		events = append(events, btctypes.NewBlockEvent(btctypes.BlockDisconnected, parent+1+int64(i), &h))
+	}
+	c.headers = c.headers[:parent-c.baseHeight]
+	for i := range headers {
+		h := headers[i]
+		height := parent + 1 + int64(i)
+		c.headers = append(c.headers, h)
+		c.index[h.BlockHash()] = height
+		events = append(events, btctypes.NewBlockEvent(btctypes.BlockConnected, height, &h))
+	}
+	return events, nil
+}
+
+// checkHeaders checks the headers are linked and sane (proof of work, timestamp). Difficulty
+// adjustments are checked by the relayer header validator and the light client.
+func (c *headerChain) checkHeaders(headers []wire.BlockHeader) error {
+	for i := range headers {
+		if i > 0 && headers[i].PrevBlock != headers[i-1].BlockHash() {
+			return fmt.Errorf("header %s doesn't extend the previous header", headers[i].BlockHash())
+		}
+		err := blockchain.CheckBlockHeaderSanity(&headers[i], c.powLimit, c.timeSource, blockchain.BFNone)
+		if err != nil {
+			return fmt.Errorf("invalid header %s: %w", headers[i].BlockHash(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+var genesis = chaincfg.RegressionNetParams.GenesisBlock.Header
+
+// forkAt mines count headers on top of prev, different from the ones mined by MineTestChain.
+func forkAt(t *testing.T, prev wire.BlockHeader, count int) []wire.BlockHeader {
+	t.Helper()
+	first := btctypes.MineTestHeader(t, &prev, prev.Timestamp.Add(10*time.Minute+time.Second),
+		chaincfg.RegressionNetParams.PowLimitBits)
+	return append([]wire.BlockHeader{first}, btctypes.MineTestChain(t, first, count-1)...)
+}
+
+func eventHeights(events []*btctypes.BlockEvent) []int64 {
+	var heights []int64
+	for _, e := range events {
+		if e.Type == btctypes.BlockDisconnected {
+			heights = append(heights, -e.Height)
+		} else {
+			heights = append(heights, e.Height)
+		}
+	}
+	return heights
+}
+
+func TestHeaderChainConnect(t *testing.T) {
+	c := newHeaderChain(&chaincfg.RegressionNetParams, 0)
+	headers := btctypes.MineTestChain(t, genesis, 5)
+
+	events, err := c.connect(headers[:3])
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, eventHeights(events))
+
+	// overlapping headers are skipped
+	events, err = c.connect(headers[1:])
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, eventHeights(events))
+	hash, height := c.tip()
+	assert.Equal(t, int64(5), height)
+	assert.Equal(t, headers[4].BlockHash(), hash)
+
+	// fork with less work is ignored
+	events, err = c.connect(forkAt(t, headers[2], 1))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// fork with more work replaces the chain above the fork point
+	fork := forkAt(t, headers[2], 3)
+	events, err = c.connect(fork)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-5, -4, 4, 5, 6}, eventHeights(events))
+	h, err := c.header(4)
+	require.NoError(t, err)
+	assert.Equal(t, fork[0], *h)
+
+	_, err = c.connect(btctypes.MineTestChain(t, headers[4], 1))
+	assert.ErrorIs(t, err, errUnconnectedHeaders)
+
+	_, err = c.header(7)
+	assert.ErrorIs(t, err, errHeaderNotFound)
+	_, err = c.header(0)
+	assert.ErrorIs(t, err, errHeaderNotFound)
+}
+
+func TestHeaderChainInvalidHeaders(t *testing.T) {
+	c := newHeaderChain(&chaincfg.RegressionNetParams, 0)
+	headers := btctypes.MineTestChain(t, genesis, 3)
+
+	// not linked
+	_, err := c.connect([]wire.BlockHeader{headers[0], headers[2]})
+	assert.Error(t, err)
+
+	// hash above the target: the mainnet minimum difficulty is way above the regtest one
+	bad := headers[0]
+	bad.Bits = chaincfg.MainNetParams.PowLimitBits
+	_, err = c.connect([]wire.BlockHeader{bad})
+	assert.Error(t, err)
+
+	_, height := c.tip()
+	assert.Equal(t, int64(0), height)
+}
+
+func TestHeaderChainLocator(t *testing.T) {
+	c := newHeaderChain(&chaincfg.RegressionNetParams, 0)
+	assert.Len(t, c.locator(), 1)
+
+	_, err := c.connect(btctypes.MineTestChain(t, genesis, 30))
+	require.NoError(t, err)
+	locator := c.locator()
+	tip, _ := c.tip()
+	assert.Equal(t, tip, *locator[0])
+	assert.Equal(t, *chaincfg.RegressionNetParams.GenesisHash, *locator[len(locator)-1])
+	// heights 30...21, then 19, 15, 7 and the genesis
+	assert.Len(t, locator, 14)
+}
+
+func TestNewHeaderChainCheckpoint(t *testing.T) {
+	params := chaincfg.MainNetParams
+	c := newHeaderChain(&params, 0)
+	last := params.Checkpoints[len(params.Checkpoints)-1]
+	hash, height := c.tip()
+	assert.Equal(t, int64(last.Height), height)
+	assert.Equal(t, *last.Hash, hash)
+	_, err := c.header(height)
+	assert.ErrorIs(t, err, errHeaderNotFound)
+
+	// the sync starts from the last checkpoint at or below the start height
+	cp := params.Checkpoints[len(params.Checkpoints)-3]
+	c = newHeaderChain(&params, int64(cp.Height)+100)
+	hash, height = c.tip()
+	assert.Equal(t, int64(cp.Height), height)
+	assert.Equal(t, *cp.Hash, hash)
+
+	c = newHeaderChain(&params, 1)
+	hash, height = c.tip()
+	assert.Equal(t, int64(0), height)
+	assert.Equal(t, *params.GenesisHash, hash)
+}
@@ -0,0 +1,353 @@
+// Package p2p implements a headers-only BTCClient speaking the Bitcoin wire protocol directly
+// to the configured peers, so headers can be relayed from any public node without RPC access.
+// Full blocks are not supported: the relayer must run without Walrus and the indexer.
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/rs/zerolog"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	relayerconfig "github.com/gonative-cc/relayer/bitcoinspv/config"
+	relayertypes "github.com/gonative-cc/relayer/bitcoinspv/types"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+var _ clients.BTCClient = (*Client)(nil)
+
+// ErrFullBlocksUnsupported is returned by the queries for full blocks.
+var ErrFullBlocksUnsupported = errors.New("p2p backend only supports block headers")
+
+// initialSyncTimeout bounds the headers sync done when the client is created.
+const initialSyncTimeout = 10 * time.Minute
+
+// Client syncs the best header chain from a peer and sends the connected and disconnected
+// blocks to the block events channel. When the peer disconnects or misbehaves, the next
+// configured peer is used.
+type Client struct {
+	params                *chaincfg.Params
+	peers                 []string
+	logger                zerolog.Logger
+	retrySleepDuration    time.Duration
+	maxRetrySleepDuration time.Duration
+
+	mu    sync.RWMutex
+	chain *headerChain
+
+	blockEventsChannel chan *btctypes.BlockEvent
+	subscribed         atomic.Bool
+	synced             chan struct{}
+	syncedOnce         sync.Once
+
+	peerMu sync.Mutex
+	peer   *peerConn
+
+	stopOnce sync.Once
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New connects to the first reachable peer and syncs the headers from the last checkpoint
+// of the network at or below p2p-start-height, the last checkpoint by default.
+func New(
+	config *relayerconfig.BTCConfig,
+	retrySleepDuration,
+	maxRetrySleepDuration time.Duration,
+	parentLogger zerolog.Logger,
+) (*Client, error) {
+	params, err := btctypes.GetChainParams(config.NetParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Peers) == 0 {
+		return nil, errors.New("no peers configured")
+	}
+	peers := make([]string, len(config.Peers))
+	for i, addr := range config.Peers {
+		peers[i] = withDefaultPort(addr, params.DefaultPort)
+	}
+
+	c := &Client{
+		params:                params,
+		peers:                 peers,
+		logger:                parentLogger.With().Str("module", "p2p").Logger(),
+		retrySleepDuration:    retrySleepDuration,
+		maxRetrySleepDuration: maxRetrySleepDuration,
+		chain:                 newHeaderChain(params, config.P2PStartHeight),
+		blockEventsChannel:    make(chan *btctypes.BlockEvent, 10000),
+		synced:                make(chan struct{}),
+		quit:                  make(chan struct{}),
+	}
+
+	p, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	c.wg.Add(1)
+	go c.run(p)
+
+	select {
+	case <-c.synced:
+	case <-time.After(initialSyncTimeout):
+		c.Stop()
+		return nil, fmt.Errorf("headers sync didn't complete within %v", initialSyncTimeout)
+	}
+	_, height := c.tip()
+	c.logger.Info().Int64("height", height).Msg("Headers synced")
+	return c, nil
+}
+
+func withDefaultPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, port)
+}
+
+// SubscribeNewBlocks starts sending block events. Blocks connected before are not sent.
+func (c *Client) SubscribeNewBlocks() {
+	c.subscribed.Store(true)
+	c.logger.Info().Msg("Successfully subscribed to newly connected/disconnected blocks via P2P")
+}
+
+// BlockEventChannel returns the channel used for block events
+func (c *Client) BlockEventChannel() <-chan *btctypes.BlockEvent {
+	return c.blockEventsChannel
+}
+
+// Stop disconnects from the peer and closes the block events channel
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.quit)
+		c.peerMu.Lock()
+		if c.peer != nil {
+			c.peer.close()
+		}
+		c.peerMu.Unlock()
+		c.wg.Wait()
+		close(c.blockEventsChannel)
+	})
+}
+
+// WaitForShutdown blocks until the peer connection is closed
+func (c *Client) WaitForShutdown() {
+	c.wg.Wait()
+}
+
+// connect connects to the first reachable peer.
+func (c *Client) connect() (*peerConn, error) {
+	_, height := c.tip()
+	var errs []error
+	for _, addr := range c.peers {
+		p, err := dialPeer(addr, c.params.Net, height)
+		if err == nil {
+			c.logger.Info().Str("peer", addr).Msg("Connected to peer")
+			return p, nil
+		}
+		c.logger.Warn().Err(err).Str("peer", addr).Msg("Failed to connect to peer")
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("failed to connect to any peer: %w", errors.Join(errs...))
+}
+
+// reconnect retries connect with an exponential backoff until a peer is reachable.
+// Returns nil when the client is stopped.
+func (c *Client) reconnect() *peerConn {
+	sleep := c.retrySleepDuration
+	for {
+		select {
+		case <-c.quit:
+			return nil
+		case <-time.After(sleep):
+		}
+		p, err := c.connect()
+		if err == nil {
+			return p
+		}
+		c.logger.Warn().Err(err).Dur("retry_in", sleep).Msg("Reconnecting to peers")
+		sleep = min(2*sleep, c.maxRetrySleepDuration)
+	}
+}
+
+func (c *Client) run(p *peerConn) {
+	defer c.wg.Done()
+	for p != nil {
+		err := c.serve(p)
+		p.close()
+		select {
+		case <-c.quit:
+			return
+		default:
+		}
+		c.logger.Warn().Err(err).Str("peer", p.addr).Msg("Disconnected from peer")
+		p = c.reconnect()
+	}
+}
+
+// serve syncs the headers from the peer and handles its messages until the connection fails.
+func (c *Client) serve(p *peerConn) error {
+	c.peerMu.Lock()
+	select {
+	case <-c.quit:
+		c.peerMu.Unlock()
+		return errors.New("client stopped")
+	default:
+	}
+	c.peer = p
+	c.peerMu.Unlock()
+
+	if err := c.requestHeaders(p); err != nil {
+		return err
+	}
+	for {
+		msg, err := p.readIdle()
+		if err != nil {
+			return err
+		}
+		switch m := msg.(type) {
+		case *wire.MsgHeaders:
+			if err := c.onHeaders(p, m); err != nil {
+				return err
+			}
+		case *wire.MsgInv:
+			for _, inv := range m.InvList {
+				if inv.Type == wire.InvTypeBlock || inv.Type == wire.InvTypeWitnessBlock {
+					if err := c.requestHeaders(p); err != nil {
+						return err
+					}
+					break
+				}
+			}
+		case *wire.MsgPing:
+			if err := p.send(wire.NewMsgPong(m.Nonce)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Client) requestHeaders(p *peerConn) error {
+	c.mu.RLock()
+	locator := c.chain.locator()
+	c.mu.RUnlock()
+	return p.getHeaders(locator)
+}
+
+// onHeaders adds the received headers to the chain and requests the next ones
+// while the peer sends full batches.
+func (c *Client) onHeaders(p *peerConn, m *wire.MsgHeaders) error {
+	headers := make([]wire.BlockHeader, len(m.Headers))
+	for i, h := range m.Headers {
+		headers[i] = *h
+	}
+
+	c.mu.Lock()
+	events, err := c.chain.connect(headers)
+	c.mu.Unlock()
+	if errors.Is(err, errUnconnectedHeaders) {
+		// a block announcement skipping blocks we don't have
+		return c.requestHeaders(p)
+	}
+	if err != nil {
+		return fmt.Errorf("peer %s sent invalid headers: %w", p.addr, err)
+	}
+
+	synced := c.isSynced()
+	if synced && c.subscribed.Load() {
+		for _, e := range events {
+			select {
+			case c.blockEventsChannel <- e:
+			case <-c.quit:
+				return nil
+			}
+		}
+	}
+	if len(m.Headers) == wire.MaxBlockHeadersPerMsg {
+		return c.requestHeaders(p)
+	}
+	if !synced {
+		c.syncedOnce.Do(func() { close(c.synced) })
+	}
+	return nil
+}
+
+func (c *Client) isSynced() bool {
+	select {
+	case <-c.synced:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) tip() (chainhash.Hash, int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.chain.tip()
+}
+
+// BaseHeight returns the height of the checkpoint the headers sync starts from. The headers
+// at or below it are unknown to the client.
+func (c *Client) BaseHeight() int64 {
+	return c.chain.baseHeight
+}
+
+// GetBTCTipBlock returns the hash and height of the best synced header
+func (c *Client) GetBTCTipBlock() (*chainhash.Hash, int64, error) {
+	hash, height := c.tip()
+	return &hash, height, nil
+}
+
+// GetBTCBlockHeaderByHeight returns the synced header at the given height
+func (c *Client) GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.chain.header(height)
+}
+
+// GetBTCTailBlocksByHeight returns the headers from the given height up to the best
+// synced header. Full blocks are not supported.
+func (c *Client) GetBTCTailBlocksByHeight(baseHeight int64, fullBlocks bool) ([]*relayertypes.IndexedBlock, error) {
+	if fullBlocks {
+		return nil, ErrFullBlocksUnsupported
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tipHeight := c.chain.tipHeight()
+	if baseHeight > tipHeight {
+		return nil, fmt.Errorf("base height %d exceeds current tip height %d", baseHeight, tipHeight)
+	}
+	blocks := make([]*relayertypes.IndexedBlock, 0, tipHeight-baseHeight+1)
+	for height := baseHeight; height <= tipHeight; height++ {
+		header, err := c.chain.header(height)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, relayertypes.NewIndexedBlock(height, wire.NewMsgBlock(header)))
+	}
+	return blocks, nil
+}
+
+// GetBTCBlockByHash is not supported by the p2p backend
+func (c *Client) GetBTCBlockByHash(*chainhash.Hash) (*relayertypes.IndexedBlock, error) {
+	return nil, ErrFullBlocksUnsupported
+}
+
+// GetBTCBlockByHeight is not supported by the p2p backend
+func (c *Client) GetBTCBlockByHeight(int64) (*relayertypes.IndexedBlock, error) {
+	return nil, ErrFullBlocksUnsupported
+}
+
+// GetBTCTxBlockHash is not supported by the p2p backend
+func (c *Client) GetBTCTxBlockHash(*chainhash.Hash) (*chainhash.Hash, error) {
+	return nil, ErrFullBlocksUnsupported
+}
@@ -0,0 +1,205 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	relayerconfig "github.com/gonative-cc/relayer/bitcoinspv/config"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+// fakePeer is an in-process regtest node serving headers over the wire protocol.
+type fakePeer struct {
+	t        *testing.T
+	listener net.Listener
+	btcnet   wire.BitcoinNet
+
+	mu      sync.Mutex
+	headers []wire.BlockHeader // headers[i] is at height i+1
+	conn    net.Conn
+}
+
+func newFakePeer(t *testing.T, headers []wire.BlockHeader) *fakePeer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &fakePeer{t: t, listener: l, btcnet: chaincfg.RegressionNetParams.Net, headers: headers}
+	t.Cleanup(func() { l.Close() })
+	go p.accept()
+	return p
+}
+
+func (p *fakePeer) addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *fakePeer) accept() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		p.conn = conn
+		p.mu.Unlock()
+		go p.serve(conn)
+	}
+}
+
+func (p *fakePeer) send(msg wire.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = wire.WriteMessage(p.conn, msg, protocolVersion, p.btcnet)
+}
+
+func (p *fakePeer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		msg, _, err := wire.ReadMessage(conn, protocolVersion, p.btcnet)
+		if err != nil {
+			return
+		}
+		switch m := msg.(type) {
+		case *wire.MsgVersion:
+			me := wire.NewNetAddressIPPort(net.IPv4zero, 0, 0)
+			p.send(wire.NewMsgVersion(me, me, 1, 0))
+			p.send(wire.NewMsgVerAck())
+		case *wire.MsgGetHeaders:
+			p.send(p.headersAfter(m.BlockLocatorHashes))
+		}
+	}
+}
+
+// headersAfter returns the headers following the first locator hash in the chain.
+func (p *fakePeer) headersAfter(locator []*chainhash.Hash) *wire.MsgHeaders {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	start := 0
+	for _, hash := range locator {
+		if *hash == *chaincfg.RegressionNetParams.GenesisHash {
+			break
+		}
+		found := false
+		for i := range p.headers {
+			if p.headers[i].BlockHash() == *hash {
+				start, found = i+1, true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	msg := wire.NewMsgHeaders()
+	for i := start; i < len(p.headers) && len(msg.Headers) < wire.MaxBlockHeadersPerMsg; i++ {
+		_ = msg.AddBlockHeader(&p.headers[i])
+	}
+	return msg
+}
+
+// mine adds count headers to the chain and returns them.
+func (p *fakePeer) mine(count int) []wire.BlockHeader {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	next := btctypes.MineTestChain(p.t, p.headers[len(p.headers)-1], count)
+	p.headers = append(p.headers, next...)
+	return next
+}
+
+func waitEvent(t *testing.T, c *Client) *btctypes.BlockEvent {
+	t.Helper()
+	select {
+	case e := <-c.BlockEventChannel():
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a block event")
+		return nil
+	}
+}
+
+func TestClient(t *testing.T) {
+	peer := newFakePeer(t, btctypes.MineTestChain(t, genesis, 3))
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable.Close()
+
+	cfg := &relayerconfig.BTCConfig{
+		NetParams: btctypes.Regtest.String(),
+		Peers:     []string{unreachable.Addr().String(), peer.addr()},
+	}
+	c, err := New(cfg, 10*time.Millisecond, 50*time.Millisecond, zerolog.Nop())
+	require.NoError(t, err)
+
+	hash, height, err := c.GetBTCTipBlock()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), height)
+	assert.Equal(t, peer.headers[2].BlockHash(), *hash)
+
+	blocks, err := c.GetBTCTailBlocksByHeight(2, false)
+	require.NoError(t, err)
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, peer.headers[1].BlockHash(), blocks[0].BlockHash())
+	_, err = c.GetBTCTailBlocksByHeight(2, true)
+	assert.ErrorIs(t, err, ErrFullBlocksUnsupported)
+	_, err = c.GetBTCBlockByHeight(2)
+	assert.ErrorIs(t, err, ErrFullBlocksUnsupported)
+
+	c.SubscribeNewBlocks()
+
+	// new block announced with a headers message
+	next := peer.mine(1)
+	announce := wire.NewMsgHeaders()
+	_ = announce.AddBlockHeader(&next[0])
+	peer.send(announce)
+	e := waitEvent(t, c)
+	assert.Equal(t, btctypes.BlockConnected, e.Type)
+	assert.Equal(t, int64(4), e.Height)
+	assert.Equal(t, next[0].BlockHash(), e.BlockHeader.BlockHash())
+
+	// new block announced with an inv message
+	next = peer.mine(1)
+	inv := wire.NewMsgInv()
+	nextHash := next[0].BlockHash()
+	_ = inv.AddInvVect(wire.NewInvVect(wire.InvTypeBlock, &nextHash))
+	peer.send(inv)
+	e = waitEvent(t, c)
+	assert.Equal(t, int64(5), e.Height)
+	assert.Equal(t, nextHash, e.BlockHeader.BlockHash())
+
+	// the client reconnects when the peer drops the connection and syncs the missed block
+	next = peer.mine(1)
+	peer.mu.Lock()
+	peer.conn.Close()
+	peer.mu.Unlock()
+	e = waitEvent(t, c)
+	assert.Equal(t, int64(6), e.Height)
+	assert.Equal(t, next[0].BlockHash(), e.BlockHeader.BlockHash())
+
+	c.Stop()
+	_, ok := <-c.BlockEventChannel()
+	assert.False(t, ok)
+}
+
+func TestNewNoReachablePeer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l.Close()
+	cfg := &relayerconfig.BTCConfig{NetParams: btctypes.Regtest.String(), Peers: []string{l.Addr().String()}}
+	_, err = New(cfg, time.Millisecond, time.Millisecond, zerolog.Nop())
+	assert.Error(t, err)
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:8333", withDefaultPort("127.0.0.1", "8333"))
+	assert.Equal(t, "127.0.0.1:18444", withDefaultPort("127.0.0.1:18444", "8333"))
+	assert.Equal(t, "[::1]:8333", withDefaultPort("::1", "8333"))
+}
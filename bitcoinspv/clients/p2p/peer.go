@@ -0,0 +1,133 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// protocolVersion is the protocol version announced to peers. It supports sendheaders.
+	protocolVersion = wire.ProtocolVersion
+	userAgentName   = "native-relayer"
+	userAgentVer    = "0.1.0"
+
+	dialTimeout      = 10 * time.Second
+	handshakeTimeout = 30 * time.Second
+	// idleTimeout disconnects peers not sending anything. Peers ping every 2 minutes.
+	idleTimeout = 5 * time.Minute
+)
+
+// peerConn is a connection to a Bitcoin node speaking the wire protocol.
+type peerConn struct {
+	addr string
+	conn net.Conn
+	net  wire.BitcoinNet
+}
+
+func dialPeer(addr string, btcnet wire.BitcoinNet, bestHeight int64) (*peerConn, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	p := &peerConn{addr: addr, conn: conn, net: btcnet}
+	if err := p.handshake(bestHeight); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake with %s failed: %w", addr, err)
+	}
+	return p, nil
+}
+
+// handshake exchanges version and verack messages and asks the peer to announce new
+// blocks with headers messages (BIP 130).
+func (p *peerConn) handshake(bestHeight int64) error {
+	if err := p.conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		return err
+	}
+	me := wire.NewNetAddressIPPort(net.IPv4zero, 0, 0)
+	you := wire.NewNetAddressIPPort(net.IPv4zero, 0, 0)
+	if tcpAddr, ok := p.conn.RemoteAddr().(*net.TCPAddr); ok {
+		you = wire.NewNetAddressIPPort(tcpAddr.IP, uint16(tcpAddr.Port), 0) //nolint:gosec
+	}
+	version := wire.NewMsgVersion(me, you, nonce, int32(bestHeight)) //nolint:gosec
+	version.ProtocolVersion = int32(protocolVersion)
+	version.DisableRelayTx = true
+	if err := version.AddUserAgent(userAgentName, userAgentVer); err != nil {
+		return err
+	}
+	if err := p.send(version); err != nil {
+		return err
+	}
+
+	var gotVersion, gotVerAck bool
+	for !gotVersion || !gotVerAck {
+		msg, err := p.read()
+		if err != nil {
+			return err
+		}
+		switch m := msg.(type) {
+		case *wire.MsgVersion:
+			if m.ProtocolVersion < int32(wire.SendHeadersVersion) {
+				return fmt.Errorf("peer protocol version %d doesn't support sendheaders", m.ProtocolVersion)
+			}
+			gotVersion = true
+			if err := p.send(wire.NewMsgVerAck()); err != nil {
+				return err
+			}
+		case *wire.MsgVerAck:
+			gotVerAck = true
+		}
+	}
+
+	if err := p.send(wire.NewMsgSendHeaders()); err != nil {
+		return err
+	}
+	return p.conn.SetDeadline(time.Time{})
+}
+
+// getHeaders requests the headers following the locator.
+func (p *peerConn) getHeaders(locator []*chainhash.Hash) error {
+	msg := wire.NewMsgGetHeaders()
+	msg.ProtocolVersion = protocolVersion
+	for _, hash := range locator {
+		if err := msg.AddBlockLocatorHash(hash); err != nil {
+			return err
+		}
+	}
+	return p.send(msg)
+}
+
+func (p *peerConn) send(msg wire.Message) error {
+	return wire.WriteMessage(p.conn, msg, protocolVersion, p.net)
+}
+
+// read returns the next known message, skipping the messages unknown to the wire package.
+func (p *peerConn) read() (wire.Message, error) {
+	for {
+		msg, _, err := wire.ReadMessage(p.conn, protocolVersion, p.net)
+		if errors.Is(err, wire.ErrUnknownMessage) {
+			continue
+		}
+		return msg, err
+	}
+}
+
+// readIdle is read with the idle timeout.
+func (p *peerConn) readIdle() (wire.Message, error) {
+	if err := p.conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+		return nil, err
+	}
+	return p.read()
+}
+
+func (p *peerConn) close() error {
+	return p.conn.Close()
+}
@@ -20,6 +20,7 @@ var (
 	ErrNoEventsFound        = errors.New("no events found for transaction digest")
 	ErrEventDataFormat      = errors.New("failed to retrieve Sui events")
 	ErrSuiTransactionFailed = errors.New("sui transaction execution failed")
+	ErrInsufficientGas      = errors.New("not enough SUI to pay the gas")
 
 	ErrGetObject = errors.New("sui GetObject")
 )
@@ -0,0 +1,212 @@
+package sui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/fardream/go-bcs/bcs"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/pattonkan/sui-go/sui/suiptb"
+	"github.com/pattonkan/sui-go/suiclient"
+)
+
+const (
+	defaultGasBudgetMargin = 0.2
+	// defaultLowBalance is 5 SUI, in MIST.
+	defaultLowBalance = 5_000_000_000
+	// maxGasPaymentCoins is the max number of gas coins of a transaction, set by the Sui protocol.
+	maxGasPaymentCoins = 256
+	// mergeCoinsThreshold is the number of coins above which all the coins (up to
+	// maxGasPaymentCoins) are used to pay the gas, so Sui merges them into a single coin.
+	mergeCoinsThreshold = 10
+	// minGasUnits is the minimum gas budget accepted by Sui, in gas units.
+	minGasUnits   = 1000
+	coinsPageSize = 50
+)
+
+// GasConfig configures how the SPVClient pays the gas of its transactions.
+// Zero values are replaced with the defaults.
+type GasConfig struct {
+	// BudgetMargin is added to the gas cost estimated by a dry run, e.g. 0.2 for 20%.
+	BudgetMargin float64
+	// MaxBudget caps the gas budget, in MIST. It's also the budget of the dry run.
+	MaxBudget uint64
+	// LowBalance is the SUI balance, in MIST, below which a warning is logged.
+	LowBalance uint64
+}
+
+func (g GasConfig) withDefaults() GasConfig {
+	if g.BudgetMargin <= 0 {
+		g.BudgetMargin = defaultGasBudgetMargin
+	}
+	if g.MaxBudget == 0 {
+		g.MaxBudget = defaultGasBudget
+	}
+	if g.LowBalance == 0 {
+		g.LowBalance = defaultLowBalance
+	}
+	return g
+}
+
+// gasCoins returns all the SUI coins of the signer, largest first, and their total balance.
+// Coins locked until a future epoch can't pay the gas and are skipped.
+func (c *SPVClient) gasCoins(ctx context.Context) ([]*suiclient.Coin, uint64, error) {
+	var coins []*suiclient.Coin
+	var cursor *string
+	for {
+		page, err := c.GetCoins(ctx, &suiclient.GetCoinsRequest{
+			Owner:  c.Signer.Address(),
+			Cursor: cursor,
+			Limit:  coinsPageSize,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("fetching Sui coins failed: %w", err)
+		}
+		for _, coin := range page.Data {
+			if coin.LockedUntilEpoch == nil || coin.LockedUntilEpoch.Sign() == 0 {
+				coins = append(coins, coin)
+			}
+		}
+		if !page.HasNextPage || page.NextCursor == nil {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	slices.SortFunc(coins, func(a, b *suiclient.Coin) int {
+		return b.Balance.Cmp(a.Balance.Int)
+	})
+	var balance uint64
+	for _, coin := range coins {
+		balance = addSaturating(balance, coin.Balance.Uint64())
+	}
+	return coins, balance, nil
+}
+
+// checkBalance updates the balance metric and warns when the balance is low.
+func (c *SPVClient) checkBalance(balance uint64) {
+	metrics.SuiGasBalance.Set(float64(balance))
+	if balance < c.gas.LowBalance {
+		c.logger.Warn().
+			Str("address", c.Signer.Address().String()).
+			Uint64("balance", balance).
+			Uint64("threshold", c.gas.LowBalance).
+			Msg("Low SUI balance, top up the relayer account to keep paying the gas")
+	}
+}
+
+// payableBalance returns the balance of the coins, sorted largest first, that can pay the gas
+// of a single transaction: at most maxGasPaymentCoins coins.
+func payableBalance(coins []*suiclient.Coin) uint64 {
+	var total uint64
+	for _, coin := range coins[:min(len(coins), maxGasPaymentCoins)] {
+		total = addSaturating(total, coin.Balance.Uint64())
+	}
+	return total
+}
+
+// selectGasCoins selects the coins, sorted largest first, paying a gas budget. When there are
+// more than mergeCoinsThreshold coins, the smallest coins are selected as well (up to
+// maxGasPaymentCoins), so Sui merges them into the first one.
+// Returns the selected coins and their total balance.
+func selectGasCoins(coins []*suiclient.Coin, budget uint64) ([]*suiclient.Coin, uint64, error) {
+	n := 0
+	var total uint64
+	for n < len(coins) && n < maxGasPaymentCoins && (n == 0 || total < budget) {
+		total = addSaturating(total, coins[n].Balance.Uint64())
+		n++
+	}
+	if n == 0 || total < budget {
+		return nil, 0, fmt.Errorf("%w: need %d MIST, the %d largest coins have %d MIST",
+			ErrInsufficientGas, budget, n, total)
+	}
+
+	selected := slices.Clone(coins[:n])
+	if len(coins) > mergeCoinsThreshold {
+		// merge the dust: add the smallest coins
+		for i := len(coins) - 1; i >= n && len(selected) < maxGasPaymentCoins; i-- {
+			selected = append(selected, coins[i])
+			total = addSaturating(total, coins[i].Balance.Uint64())
+		}
+	}
+	return selected, total, nil
+}
+
+// estimateGasBudget returns the gas budget for a transaction given the gas cost of its dry run.
+// The storage rebate is not subtracted: the budget must cover the computation and storage
+// costs before the rebate.
+func estimateGasBudget(used suiclient.GasCostSummary, margin float64, gasPrice, maxBudget uint64) uint64 {
+	cost := addSaturating(used.ComputationCost.Uint64(), used.StorageCost.Uint64())
+	budget := uint64(math.Ceil(float64(cost) * (1 + margin)))
+	budget = max(budget, minGasUnits*gasPrice)
+	return min(budget, maxBudget)
+}
+
+// buildTransaction selects the gas coins and the gas budget of a PTB with a dry run.
+// Returns the BCS encoded transaction data.
+func (c *SPVClient) buildTransaction(ctx context.Context, pt suiptb.ProgrammableTransaction) ([]byte, error) {
+	price, err := c.GetReferenceGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Sui reference gas price failed: %w", err)
+	}
+	gasPrice := price.Uint64()
+
+	coins, balance, err := c.gasCoins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.checkBalance(balance)
+
+	// dry run with the largest budget we can pay
+	dryRunBudget := min(c.gas.MaxBudget, payableBalance(coins))
+	payment, _, err := selectGasCoins(coins, dryRunBudget)
+	if err != nil {
+		return nil, err
+	}
+	sender := c.Signer.Address()
+	tx := suiptb.NewTransactionData(sender, pt, suiclient.Coins(payment).CoinRefs(), dryRunBudget, gasPrice)
+	txBytes, err := bcs.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize Sui transaction %w", err)
+	}
+	dryRun, err := c.DryRunTransaction(ctx, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("sui ptb dry run failed: %w", err)
+	}
+	effects := dryRun.Effects.Data.V1
+	if effects == nil {
+		return nil, fmt.Errorf("%w: dry run returned no effects", ErrSuiTransactionFailed)
+	}
+	if !dryRun.Effects.Data.IsSuccess() {
+		return nil, fmt.Errorf("%w: dry run status: %s, error: %s",
+			ErrSuiTransactionFailed, effects.Status.Status, effects.Status.Error)
+	}
+
+	budget := estimateGasBudget(effects.GasUsed, c.gas.BudgetMargin, gasPrice, c.gas.MaxBudget)
+	payment, paid, err := selectGasCoins(coins, budget)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().
+		Uint64("gas_budget", budget).
+		Uint64("gas_price", gasPrice).
+		Int("gas_coins", len(payment)).
+		Uint64("gas_coins_balance", paid).
+		Msg("Estimated gas")
+
+	tx = suiptb.NewTransactionData(sender, pt, suiclient.Coins(payment).CoinRefs(), budget, gasPrice)
+	txBytes, err = bcs.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize Sui transaction %w", err)
+	}
+	return txBytes, nil
+}
+
+func addSaturating(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
@@ -0,0 +1,84 @@
+package sui
+
+import (
+	"testing"
+
+	"github.com/pattonkan/sui-go/sui"
+	"github.com/pattonkan/sui-go/suiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCoins(balances ...uint64) []*suiclient.Coin {
+	coins := make([]*suiclient.Coin, len(balances))
+	for i, b := range balances {
+		coins[i] = &suiclient.Coin{Balance: sui.NewBigInt(b)}
+	}
+	return coins
+}
+
+func balances(coins []*suiclient.Coin) []uint64 {
+	res := make([]uint64, len(coins))
+	for i, c := range coins {
+		res[i] = c.Balance.Uint64()
+	}
+	return res
+}
+
+func TestSelectGasCoins(t *testing.T) {
+	coins := testCoins(100, 50, 20, 5)
+
+	selected, total, err := selectGasCoins(coins, 120)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100, 50}, balances(selected))
+	assert.Equal(t, uint64(150), total)
+
+	// a coin is always selected
+	selected, _, err = selectGasCoins(coins, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100}, balances(selected))
+
+	_, _, err = selectGasCoins(coins, 200)
+	assert.ErrorIs(t, err, ErrInsufficientGas)
+	_, _, err = selectGasCoins(nil, 0)
+	assert.ErrorIs(t, err, ErrInsufficientGas)
+
+	// many coins: the dust is merged in the payment
+	many := make([]uint64, mergeCoinsThreshold+1)
+	for i := range many {
+		many[i] = uint64(len(many) - i)
+	}
+	selected, total, err = selectGasCoins(testCoins(many...), 11)
+	require.NoError(t, err)
+	assert.Len(t, selected, len(many))
+	assert.Equal(t, uint64(11), selected[0].Balance.Uint64())
+	assert.Equal(t, uint64(66), total)
+}
+
+func TestPayableBalance(t *testing.T) {
+	assert.Equal(t, uint64(0), payableBalance(nil))
+	assert.Equal(t, uint64(175), payableBalance(testCoins(100, 50, 20, 5)))
+
+	// only maxGasPaymentCoins coins pay the gas
+	many := make([]uint64, maxGasPaymentCoins+10)
+	for i := range many {
+		many[i] = 1
+	}
+	coins := testCoins(many...)
+	assert.Equal(t, uint64(maxGasPaymentCoins), payableBalance(coins))
+	_, _, err := selectGasCoins(coins, payableBalance(coins))
+	assert.NoError(t, err)
+}
+
+func TestEstimateGasBudget(t *testing.T) {
+	used := suiclient.GasCostSummary{
+		ComputationCost: sui.NewBigInt(1_000_000),
+		StorageCost:     sui.NewBigInt(4_000_000),
+		StorageRebate:   sui.NewBigInt(3_000_000),
+	}
+	assert.Equal(t, uint64(6_000_000), estimateGasBudget(used, 0.2, 1000, defaultGasBudget))
+	// capped
+	assert.Equal(t, uint64(5_500_000), estimateGasBudget(used, 0.2, 1000, 5_500_000))
+	// at least the Sui minimum budget
+	assert.Equal(t, uint64(minGasUnits*10_000), estimateGasBudget(used, 0.2, 10_000, defaultGasBudget))
+}
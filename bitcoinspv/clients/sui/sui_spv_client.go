@@ -3,15 +3,18 @@ package sui
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/fardream/go-bcs/bcs"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/gonative-cc/relayer/signer"
 	"github.com/pattonkan/sui-go/sui"
 	"github.com/pattonkan/sui-go/sui/suiptb"
 	"github.com/pattonkan/sui-go/suiclient"
-	"github.com/pattonkan/sui-go/suisigner"
 	"github.com/rs/zerolog"
 )
 
@@ -19,42 +22,53 @@ const (
 	insertHeadersFunc  = "insert_headers"
 	containsBlockFunc  = "exist"
 	getChainTipFunc    = "head"
+	getLightBlockFunc  = "get_light_block_by_hash"
 	verifySPVFunc      = "verify_tx"
 	newBlockHeaderFunc = "new"
 	lcModule           = "light_client"
 	blockHeaderModule  = "header"
 	blockHeaderType    = "BlockHeader"
-	// TODO: Use better defaultGasBudget
+	// defaultGasBudget is the default max gas budget: 1 SUI, in MIST.
 	defaultGasBudget = 1000000000
+	// maxContainsBlocksBatch is the max number of `exist` calls in a single PTB.
+	// Sui limits a PTB to 1024 commands.
+	maxContainsBlocksBatch = 512
 )
 
 // SPVClient implements the BitcoinSPV interface, interacting with a
 // Bitcoin SPV light client deployed as a smart contract on Sui
 type SPVClient struct {
 	*suiclient.ClientImpl
-	*suisigner.Signer
+	Signer      signer.Signer
 	LCPkgID     *sui.PackageId
 	BTCLibPkgID *sui.PackageId
 	LcObjArg    suiptb.CallArg
-	logger      zerolog.Logger
+	// dryRun makes InsertHeaders dev-inspect the transaction instead of executing it.
+	dryRun bool
+	gas    GasConfig
+	logger zerolog.Logger
 }
 
 var _ clients.BitcoinSPV = &SPVClient{}
 
 // New BTCLIghtClientObject creates a new SPVClient instance.
 // lcObjID and lcPkgID must be Sui Object ID as HEX.
+// With dryRun, header transactions are dev-inspected and never executed.
+// gasCfg sets how the gas is paid; zero values are replaced with the defaults.
 func New(
 	suiClient *suiclient.ClientImpl,
-	signer *suisigner.Signer,
+	txSigner signer.Signer,
 	lcObjID string,
 	lcPkgID string,
 	btcLibPkg string,
+	dryRun bool,
+	gasCfg GasConfig,
 	parentLogger zerolog.Logger,
 ) (clients.BitcoinSPV, error) {
 	if suiClient == nil {
 		return nil, ErrSuiClientNil
 	}
-	if signer == nil {
+	if txSigner == nil {
 		return nil, ErrSignerNill
 	}
 
@@ -101,11 +115,13 @@ func New(
 
 	return &SPVClient{
 		ClientImpl:  suiClient,
-		Signer:      signer,
+		Signer:      txSigner,
 		logger:      configureClientLogger(parentLogger),
 		LCPkgID:     LCPkgID,
 		BTCLibPkgID: BTCLibPkgParsed,
 		LcObjArg:    lcObjArg,
+		dryRun:      dryRun,
+		gas:         gasCfg.withDefaults(),
 	}, nil
 }
 
@@ -114,9 +130,12 @@ func configureClientLogger(parentLogger zerolog.Logger) zerolog.Logger {
 }
 
 // InsertHeaders adds new Bitcoin block headers to the light client's chain using a PTB.
-func (c *SPVClient) InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) error {
+// Returns the digest of the executed transaction.
+// In dry-run mode the PTB is dev-inspected instead: the light client is not updated and
+// the digest of the inspected transaction is returned.
+func (c *SPVClient) InsertHeaders(ctx context.Context, blockHeaders []wire.BlockHeader) (string, error) {
 	if len(blockHeaders) == 0 {
-		return ErrNoBlockHeaders
+		return "", ErrNoBlockHeaders
 	}
 
 	ptb := suiptb.NewTransactionDataTransactionBuilder()
@@ -126,12 +145,12 @@ func (c *SPVClient) InsertHeaders(ctx context.Context, blockHeaders []wire.Block
 	for _, header := range blockHeaders {
 		headerBytes, err := blockHeaderToBytes(header)
 		if err != nil {
-			return fmt.Errorf("failed to serialize block header to bytes: %w", err)
+			return "", fmt.Errorf("failed to serialize block header to bytes: %w", err)
 		}
 
 		headerArg, err := ptb.Pure(headerBytes)
 		if err != nil {
-			return fmt.Errorf("failed to create pure argument from header bytes: %w", err)
+			return "", fmt.Errorf("failed to create pure argument from header bytes: %w", err)
 		}
 		header := ptb.Command(suiptb.Command{
 			MoveCall: &suiptb.ProgrammableMoveCall{
@@ -176,24 +195,179 @@ func (c *SPVClient) InsertHeaders(ctx context.Context, blockHeaders []wire.Block
 	})
 
 	c.logger.Debug().Msgf("Calling insert headers with %d block headers", len(blockHeaders))
+	if c.dryRun {
+		return c.dryRunPTB(ctx, ptb, len(blockHeaders))
+	}
 	return c.signAndExecutePTB(ctx, ptb.Finish())
 }
 
+// moveAbortRe matches the abort code and the command index of a MoveAbort execution error, e.g.
+// "MoveAbort(MoveLocation { ... }, 5) in command 2".
+var moveAbortRe = regexp.MustCompile(`^MoveAbort\(.*, (\d+)\) in command (\d+)$`)
+
+// moveAbortCode extracts the abort code and the command index from a MoveAbort execution error.
+func moveAbortCode(execErr string) (code uint64, command int, ok bool) {
+	m := moveAbortRe.FindStringSubmatch(execErr)
+	if m == nil {
+		return 0, 0, false
+	}
+	code, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	command, err = strconv.Atoi(m[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return code, command, true
+}
+
+// dryRunPTB dev-inspects the insert headers PTB and logs the effects it would have.
+// Returns the digest of the inspected transaction, or ErrSuiTransactionFailed when the
+// execution would fail, so the headers are not recorded as submitted.
+func (c *SPVClient) dryRunPTB(
+	ctx context.Context,
+	ptb *suiptb.ProgrammableTransactionBuilder,
+	numHeaders int,
+) (string, error) {
+	resp, err := c.devInspectTransactionBlock(ctx, ptb)
+	if err != nil {
+		return "", fmt.Errorf("sui ptb dev-inspect failed: %w", err)
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%w: dev-inspect error: %s", ErrSuiTransactionFailed, resp.Error)
+	}
+	effects := resp.Effects.Data.V1
+	if effects == nil {
+		return "", fmt.Errorf("%w: dev-inspect returned no effects", ErrSuiTransactionFailed)
+	}
+
+	logger := c.logger.With().
+		Bool("dry_run", true).
+		Int("headers", numHeaders).
+		Str("digest", effects.TransactionDigest.String()).
+		Int64("gas_estimate", resp.Effects.Data.GasFee()).
+		Int("events", len(resp.Events)).
+		Int("mutated", len(effects.Mutated)).
+		Int("created", len(effects.Created)).
+		Logger()
+	if effects.Status.Status == suiclient.ExecutionStatusSuccess {
+		logger.Info().Msg("Insert headers would succeed")
+		return effects.TransactionDigest.String(), nil
+	}
+
+	ev := logger.Warn().Str("status", effects.Status.Status).Str("error", effects.Status.Error)
+	if code, command, ok := moveAbortCode(effects.Status.Error); ok {
+		ev = ev.Uint64("abort_code", code).Int("command", command)
+	}
+	ev.Msg("Insert headers would fail")
+	return "", fmt.Errorf("%w: dev-inspect status: %s, error: %s",
+		ErrSuiTransactionFailed, effects.Status.Status, effects.Status.Error)
+}
+
 // ContainsBlock checks if the light client's chain includes a block with the given hash.
 func (c *SPVClient) ContainsBlock(ctx context.Context, blockHash chainhash.Hash) (bool, error) {
+	res, err := c.ContainsBlocks(ctx, []chainhash.Hash{blockHash})
+	if err != nil {
+		return false, err
+	}
+	return res[0], nil
+}
+
+// ContainsBlocks checks which of the given block hashes are included in the light client's chain.
+// The hashes are checked in batches of maxContainsBlocksBatch, one dev-inspect call per batch.
+func (c *SPVClient) ContainsBlocks(ctx context.Context, blockHashes []chainhash.Hash) ([]bool, error) {
+	results := make([]bool, 0, len(blockHashes))
+	for start := 0; start < len(blockHashes); start += maxContainsBlocksBatch {
+		end := min(start+maxContainsBlocksBatch, len(blockHashes))
+		res, err := c.containsBlocks(ctx, blockHashes[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// containsBlocks checks the given block hashes with a single PTB, one `exist` call per hash.
+func (c *SPVClient) containsBlocks(ctx context.Context, blockHashes []chainhash.Hash) ([]bool, error) {
 	ptb := suiptb.NewTransactionDataTransactionBuilder()
 
-	b, err := bcs.Marshal(blockHash[:])
+	for _, blockHash := range blockHashes {
+		b, err := bcs.Marshal(blockHash[:])
+		if err != nil {
+			return nil, err
+		}
+
+		err = ptb.MoveCall(
+			c.LCPkgID,
+			lcModule,
+			containsBlockFunc,
+			[]sui.TypeTag{},
+			[]suiptb.CallArg{c.LcObjArg, {Pure: &b}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.devInspectTransactionBlock(ctx, ptb)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	if !resp.Effects.Data.IsSuccess() {
+		return nil, fmt.Errorf("%w: function '%s' status: %s, error: %s",
+			ErrSuiTransactionFailed, containsBlockFunc, resp.Effects.Data.V1.Status.Status, resp.Effects.Data.V1.Status.Error)
+	}
+	if len(resp.Results) != len(blockHashes) {
+		return nil, fmt.Errorf("unexpected number of results when checking if SPV contains blocks. Expecting %d, got: %d",
+			len(blockHashes), len(resp.Results))
+	}
+
+	results := make([]bool, len(blockHashes))
+	for i := range resp.Results {
+		resultVal := resp.Results[i].ReturnValues[0]
+		if resultVal.TypeTag.Bool == nil {
+			return nil, fmt.Errorf(
+				"unexpected return type when checking if SPV contains block. Expecting bool, got: %v",
+				resultVal.TypeTag)
+		}
+		if err := bcs.UnmarshalAll(resultVal.Data, &results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// VerifyTx checks the merkle proof of a transaction in the block at the given height with the
+// light client `verify_tx` function.
+func (c *SPVClient) VerifyTx(ctx context.Context, height int64, proof *types.MerkleProof) (bool, error) {
+	if height < 0 {
+		return false, fmt.Errorf("invalid height %d", height)
+	}
+	branch := make([][]byte, len(proof.Branch))
+	for i := range proof.Branch {
+		branch[i] = proof.Branch[i][:]
+	}
+	args := []any{uint64(height), proof.TxID[:], branch, uint64(proof.TxIndex)}
+	callArgs := make([]suiptb.CallArg, 0, 1+len(args))
+	callArgs = append(callArgs, c.LcObjArg)
+	for _, arg := range args {
+		b, err := bcs.Marshal(arg)
+		if err != nil {
+			return false, err
+		}
+		callArgs = append(callArgs, suiptb.CallArg{Pure: &b})
 	}
 
-	err = ptb.MoveCall(
+	ptb := suiptb.NewTransactionDataTransactionBuilder()
+	err := ptb.MoveCall(
 		c.LCPkgID,
 		lcModule,
-		containsBlockFunc,
+		verifySPVFunc,
 		[]sui.TypeTag{},
-		[]suiptb.CallArg{c.LcObjArg, {Pure: &b}},
+		callArgs,
 	)
 	if err != nil {
 		return false, err
@@ -203,32 +377,54 @@ func (c *SPVClient) ContainsBlock(ctx context.Context, blockHash chainhash.Hash)
 	if err != nil {
 		return false, err
 	}
-
 	if !resp.Effects.Data.IsSuccess() {
 		return false, fmt.Errorf("%w: function '%s' status: %s, error: %s",
-			ErrSuiTransactionFailed, containsBlockFunc, resp.Effects.Data.V1.Status.Status, resp.Effects.Data.V1.Status.Error)
+			ErrSuiTransactionFailed, verifySPVFunc, resp.Effects.Data.V1.Status.Status, resp.Effects.Data.V1.Status.Error)
 	}
 
-	var result bool
 	resultVal := resp.Results[0].ReturnValues[0]
 	if resultVal.TypeTag.Bool == nil {
 		return false, fmt.Errorf(
-			"unexpected return type when checking if SPV contains block. Expecting bool, got: %v",
+			"unexpected return type when verifying tx. Expecting bool, got: %v",
 			resultVal.TypeTag)
 	}
-	return result, bcs.UnmarshalAll(resultVal.Data, &result)
+	var valid bool
+	if err := bcs.UnmarshalAll(resultVal.Data, &valid); err != nil {
+		return false, err
+	}
+	return valid, nil
 }
 
-// GetLatestBlockInfo returns the block hash and height of the best block header.
+// GetLatestBlockInfo returns the block hash, height and chain work of the best block header.
 func (c *SPVClient) GetLatestBlockInfo(ctx context.Context) (*clients.BlockInfo, error) {
+	return c.lightBlockInfo(ctx, getChainTipFunc, []suiptb.CallArg{c.LcObjArg})
+}
+
+// GetBlockInfo returns the block hash, height and chain work of a block header known to
+// the light client.
+func (c *SPVClient) GetBlockInfo(ctx context.Context, blockHash chainhash.Hash) (*clients.BlockInfo, error) {
+	b, err := bcs.Marshal(blockHash[:])
+	if err != nil {
+		return nil, err
+	}
+	return c.lightBlockInfo(ctx, getLightBlockFunc, []suiptb.CallArg{c.LcObjArg, {Pure: &b}})
+}
+
+// lightBlockInfo calls a light client function returning a LightBlock and converts the
+// result to BlockInfo.
+func (c *SPVClient) lightBlockInfo(
+	ctx context.Context,
+	function string,
+	args []suiptb.CallArg,
+) (*clients.BlockInfo, error) {
 	ptb := suiptb.NewTransactionDataTransactionBuilder()
 
 	err := ptb.MoveCall(
 		c.LCPkgID,
 		lcModule,
-		getChainTipFunc,
+		function,
 		[]sui.TypeTag{},
-		[]suiptb.CallArg{c.LcObjArg},
+		args,
 	)
 	if err != nil {
 		return nil, err
@@ -240,14 +436,14 @@ func (c *SPVClient) GetLatestBlockInfo(ctx context.Context) (*clients.BlockInfo,
 	}
 	if !resp.Effects.Data.IsSuccess() {
 		return nil, fmt.Errorf("%w: function '%s' status: %s, error: %s",
-			ErrSuiTransactionFailed, getChainTipFunc, resp.Effects.Data.V1.Status.Status, resp.Effects.Data.V1.Status.Error)
+			ErrSuiTransactionFailed, function, resp.Effects.Data.V1.Status.Status, resp.Effects.Data.V1.Status.Error)
 	}
 
 	var result LightBlock
 	resultVal := resp.Results[0].ReturnValues[0]
 	if resultVal.TypeTag.Struct == nil {
 		return nil, fmt.Errorf(
-			"unexpected return type when checking SPV latest block info. Expecting struct, got: %v",
+			"unexpected return type when checking SPV block info. Expecting struct, got: %v",
 			resultVal.TypeTag)
 	}
 	if err = bcs.UnmarshalAll(resultVal.Data, &result); err != nil {
@@ -263,7 +459,8 @@ func (c *SPVClient) GetLatestBlockInfo(ctx context.Context) (*clients.BlockInfo,
 	blockInfo := &clients.BlockInfo{
 		Hash: &hash,
 		// #nosec G115
-		Height: int64(result.Height),
+		Height:    int64(result.Height),
+		ChainWork: result.Work(),
 	}
 
 	return blockInfo, nil
@@ -276,34 +473,24 @@ func (c *SPVClient) Stop() {
 }
 
 // signAndExecutePTB is a helper function to sign and execute a PTB transaction on the Sui blockchain.
+// The gas budget is estimated with a dry run and paid with the signer coins.
+// Returns the transaction digest.
 func (c *SPVClient) signAndExecutePTB(
 	ctx context.Context,
 	pt suiptb.ProgrammableTransaction,
-) error {
-	coinPages, err := c.GetCoins(ctx, &suiclient.GetCoinsRequest{
-		Owner: c.Address,
-		Limit: 5,
-	})
-	coins := suiclient.Coins(coinPages.Data).CoinRefs()
-
+) (string, error) {
+	txBytes, err := c.buildTransaction(ctx, pt)
 	if err != nil {
-		return fmt.Errorf("fetching Sui coins failed %w", err)
-	}
-
-	tx := suiptb.NewTransactionData(c.Address, pt, coins, defaultGasBudget, suiclient.DefaultGasPrice)
-
-	txBytes, err := bcs.Marshal(tx)
-	if err != nil {
-		return fmt.Errorf("failed to serialize Sui transaction %w", err)
+		return "", err
 	}
 	options := &suiclient.SuiTransactionBlockResponseOptions{
 		ShowEffects:       true,
 		ShowObjectChanges: true,
 	}
 
-	signedResp, err := c.SignAndExecuteTransaction(ctx, c.Signer, txBytes, options)
+	signedResp, err := signer.SignAndExecute(ctx, c.ClientImpl, c.Signer, txBytes, options)
 	if err != nil {
-		return fmt.Errorf("sui pbt transaction submission failed: %w", err)
+		return "", fmt.Errorf("sui pbt transaction submission failed: %w", err)
 	}
 
 	c.logger.Info().Msgf("%s", signedResp.Effects.Data.V1.Status.Error)
@@ -314,11 +501,11 @@ func (c *SPVClient) signAndExecutePTB(
 	// Thats why we MUST inspect the `Effects.Status` field.
 	// It will tell us about execution errors like: Abort, OutOfGas etc.
 	if !signedResp.Effects.Data.IsSuccess() {
-		return fmt.Errorf("%w: for ptb status: %s, error: %s",
+		return "", fmt.Errorf("%w: for ptb status: %s, error: %s",
 			ErrSuiTransactionFailed, signedResp.Effects.Data.V1.Status.Status, signedResp.Effects.Data.V1.Status.Error)
 	}
 
-	return nil
+	return signedResp.Digest.String(), nil
 }
 
 func (c *SPVClient) devInspectTransactionBlock(
@@ -335,7 +522,7 @@ func (c *SPVClient) devInspectTransactionBlock(
 		return nil, err
 	}
 	r := suiclient.DevInspectTransactionBlockRequest{
-		SenderAddress: c.Address,
+		SenderAddress: c.Signer.Address(),
 		TxKindBytes:   txBytes,
 	}
 
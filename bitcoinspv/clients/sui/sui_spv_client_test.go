@@ -2,14 +2,15 @@ package sui
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/gonative-cc/relayer/signer"
 	"github.com/pattonkan/sui-go/suiclient"
-	"github.com/pattonkan/sui-go/suisigner"
-	"github.com/pattonkan/sui-go/suisigner/suicrypto"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +28,7 @@ func setupIntegrationTest(t *testing.T) (context.Context, clients.BitcoinSPV) {
 	t.Helper()
 
 	cl := suiclient.NewClient(localRPC)
-	s, err := suisigner.NewSignerWithMnemonic(localMnemonic, suicrypto.KeySchemeFlagDefault)
+	s, err := signer.NewLocalFromMnemonic(localMnemonic)
 	assert.Nil(t, err)
 	client, err := New(
 		cl,
@@ -35,6 +36,8 @@ func setupIntegrationTest(t *testing.T) (context.Context, clients.BitcoinSPV) {
 		lightClientObjectID,
 		lcPkgID,
 		btcLibPkg,
+		false,
+		GasConfig{},
 		zerolog.Logger{},
 	)
 	assert.Nil(t, err)
@@ -52,7 +55,7 @@ func TestInsertHeader(t *testing.T) {
 
 	headers := []wire.BlockHeader{header}
 
-	err = client.InsertHeaders(ctx, headers)
+	_, err = client.InsertHeaders(ctx, headers)
 	assert.Nil(t, err)
 }
 
@@ -66,7 +69,7 @@ func TestInsertHeaderAlreadyExistErr(t *testing.T) {
 
 	headers := []wire.BlockHeader{header}
 
-	err = client.InsertHeaders(ctx, headers)
+	_, err = client.InsertHeaders(ctx, headers)
 	assert.NotNil(t, err)
 }
 
@@ -89,6 +92,42 @@ func TestContainsBlock(t *testing.T) {
 	assert.False(t, exist, "Non-existent block should not exist")
 }
 
+func TestContainsBlocks(t *testing.T) {
+	t.Skip("Test to be run locally for debugging purposes only")
+	ctx, client := setupIntegrationTest(t)
+
+	rawHeaderHex := "00000030759e91f85448e42780695a7c71a6e4f4e845ecd895b19fafaeb6f5e3c030e62233287429255f254a463d90b998ba5523634da7c67ef873268e1db40d1526d5583d5b6167ffff7f2000000000"
+	header, err := BlockHeaderFromHex(rawHeaderHex)
+	assert.Nil(t, err)
+	nonExistentHash, _ := chainhash.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")
+
+	exist, err := client.ContainsBlocks(ctx, []chainhash.Hash{header.BlockHash(), *nonExistentHash})
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, false}, exist)
+}
+
+func TestVerifyTx(t *testing.T) {
+	t.Skip("Test to be run locally for debugging purposes only")
+	ctx, client := setupIntegrationTest(t)
+
+	// regtest block with the coinbase transaction only: the txid is the merkle root
+	rawHeaderHex := "00000030759e91f85448e42780695a7c71a6e4f4e845ecd895b19fafaeb6f5e3c030e62233287429255f254a463d90b998ba5523634da7c67ef873268e1db40d1526d5583d5b6167ffff7f2000000000"
+	header, err := BlockHeaderFromHex(rawHeaderHex)
+	assert.Nil(t, err)
+	blockInfo, err := client.GetBlockInfo(ctx, header.BlockHash())
+	assert.Nil(t, err)
+
+	proof := &types.MerkleProof{TxID: header.MerkleRoot}
+	valid, err := client.VerifyTx(ctx, blockInfo.Height, proof)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	proof.TxID = chainhash.Hash{1}
+	valid, err = client.VerifyTx(ctx, blockInfo.Height, proof)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+}
+
 func TestGetHeaderChainTip(t *testing.T) {
 	t.Skip("Test to be run locally for debugging purposes only")
 	ctx, client := setupIntegrationTest(t)
@@ -101,3 +140,25 @@ func TestGetHeaderChainTip(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, exist, "Chain tip block should exist")
 }
+
+func TestLightBlockWork(t *testing.T) {
+	var lb LightBlock
+	// 0x0102 as a little-endian u256
+	lb.ChainWork[0] = 0x02
+	lb.ChainWork[1] = 0x01
+	assert.Equal(t, big.NewInt(0x0102), lb.Work())
+	// ChainWork is not modified
+	assert.Equal(t, uint8(0x02), lb.ChainWork[0])
+}
+
+func TestMoveAbortCode(t *testing.T) {
+	code, command, ok := moveAbortCode(`MoveAbort(MoveLocation { module: ModuleId { address: 063d, ` +
+		`name: Identifier("light_client") }, function: 5, instruction: 20, ` +
+		`function_name: Some("insert_headers") }, 1) in command 2`)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), code)
+	assert.Equal(t, 2, command)
+
+	_, _, ok = moveAbortCode("InsufficientGas")
+	assert.False(t, ok)
+}
@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"encoding/hex"
 	"errors"
+	"math/big"
+	"slices"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
@@ -78,6 +80,14 @@ func (lb LightBlock) BlockHash() (chainhash.Hash, error) {
 	return *hash, nil
 }
 
+// Work returns the chain work of the light block. ChainWork is a BCS encoded u256,
+// so it's stored in little-endian order.
+func (lb LightBlock) Work() *big.Int {
+	be := lb.ChainWork
+	slices.Reverse(be[:])
+	return new(big.Int).SetBytes(be[:])
+}
+
 func blockHeaderToBytes(header wire.BlockHeader) ([]byte, error) {
 	var w bytes.Buffer
 	if err := header.Serialize(&w); err != nil {
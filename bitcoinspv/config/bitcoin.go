@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
 	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
@@ -21,6 +23,8 @@ const (
 	defaultBtcNodeEstimateMode = "CONSERVATIVE"
 	// ZMQ endpoints
 	defaultZmqSeqEndpoint = "tcp://127.0.0.1:29000"
+	// Polling backend
+	defaultPollInterval = 10 * time.Second
 )
 
 var (
@@ -36,6 +40,9 @@ type BTCConfig struct {
 	NetParams        string                    `mapstructure:"net-params"`
 	BtcBackend       btctypes.SupportedBackend `mapstructure:"btc-backend"`
 	ZmqSeqEndpoint   string                    `mapstructure:"zmq-seq-endpoint"`
+	PollInterval     time.Duration             `mapstructure:"poll-interval"`
+	Peers            []string                  `mapstructure:"peers"`
+	P2PStartHeight   int64                     `mapstructure:"p2p-start-height"`
 	DisableClientTLS bool                      `mapstructure:"no-client-tls"`
 }
 
@@ -66,14 +73,66 @@ func (cfg *BTCConfig) validateBitcoindConfig() error {
 	return nil
 }
 
+func (cfg *BTCConfig) validatePollingConfig() error {
+	if cfg.BtcBackend != btctypes.Polling && cfg.BtcBackend != btctypes.Esplora {
+		return nil
+	}
+
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive in config file: %v", cfg.PollInterval)
+	}
+
+	return nil
+}
+
+func (cfg *BTCConfig) validateEsploraConfig() error {
+	if cfg.BtcBackend != btctypes.Esplora {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("esplora endpoint must be an http(s) URL in config file: %s", cfg.Endpoint)
+	}
+
+	return nil
+}
+
+func (cfg *BTCConfig) validateP2PConfig() error {
+	if cfg.BtcBackend != btctypes.P2P {
+		return nil
+	}
+
+	if len(cfg.Peers) == 0 {
+		return fmt.Errorf("peers cannot be empty in config file for the %s backend", btctypes.P2P)
+	}
+
+	if cfg.P2PStartHeight < 0 {
+		return fmt.Errorf("p2p start height cannot be negative in config file: %d", cfg.P2PStartHeight)
+	}
+
+	return nil
+}
+
 // Validate does validation checks on bitcoin node configuration values
 func (cfg *BTCConfig) Validate() error {
 	if err := cfg.validateBasicConfig(); err != nil {
 		return err
 	}
 
-	err := cfg.validateBitcoindConfig()
-	return err
+	if err := cfg.validateBitcoindConfig(); err != nil {
+		return err
+	}
+
+	if err := cfg.validatePollingConfig(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateEsploraConfig(); err != nil {
+		return err
+	}
+
+	return cfg.validateP2PConfig()
 }
 
 // DefaultBTCConfig returns the default values for
@@ -87,6 +146,7 @@ func DefaultBTCConfig() BTCConfig {
 		Username:         defaultBtcNodeRPCUser,
 		Password:         defaultBtcNodeRPCPass,
 		ZmqSeqEndpoint:   defaultZmqSeqEndpoint,
+		PollInterval:     defaultPollInterval,
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/btcsuite/btcd/btcutil"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
 	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -50,6 +51,15 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// the p2p backend only provides headers
+	if c.BTC.BtcBackend == btctypes.P2P && (c.Relayer.StoreBlocksInWalrus || c.Relayer.IndexerURL != "") {
+		return fmt.Errorf("invalid config: the %s btc backend can't be used with Walrus or the indexer",
+			btctypes.P2P)
+	}
+	if c.BTC.BtcBackend == btctypes.P2P && c.Relayer.ProofAPI {
+		return fmt.Errorf("invalid config: the %s btc backend can't serve the proof API", btctypes.P2P)
+	}
+
 	return nil
 }
 
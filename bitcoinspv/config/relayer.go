@@ -14,6 +14,13 @@ const (
 	minBTCCacheSize              = 1000
 	minheadersChunkSize          = 1
 	defaultConfirmationDepth     = 6
+	defaultLeaseTTL              = 15 * time.Second
+	// DefaultWalrusUploadWorkers is the number of concurrent Walrus uploads when
+	// walrus-upload-workers is not set.
+	DefaultWalrusUploadWorkers = 2
+	// DefaultWalrusRenewBeforeEpochs is the number of epochs before their expiration at
+	// which Walrus blobs are renewed when walrus-renew-before-epochs is not set.
+	DefaultWalrusRenewBeforeEpochs = 1
 )
 
 // RelayerConfig defines configuration for the spv relayer.
@@ -37,16 +44,66 @@ type RelayerConfig struct {
 	BTCConfirmationDepth int64 `mapstructure:"confirmation_depth"`
 	// HeadersChunkSize is maximum number of headers in a MsgInsertHeaders message
 	HeadersChunkSize uint32 `mapstructure:"headers-chunk-size"`
+	// SkipHeaderValidation disables the local consensus checks (proof-of-work, difficulty,
+	// timestamps and linkage) of headers before they are submitted to the light client.
+	SkipHeaderValidation bool `mapstructure:"skip-header-validation"`
 	// ProcessBlockTimeout is the timeout duration for processing a single block.
 	ProcessBlockTimeout time.Duration `mapstructure:"process-block-timeout"`
 	// IndexerConfig
 	IndexerURL string `mapstructure:"indexer-url"`
+	// StateDBFile is the path to the SQLite file where the relayer keeps submitted chunks
+	// and sync checkpoints. Empty disables the local state store.
+	StateDBFile string `mapstructure:"state-db-file"`
+	// MetricsListenAddr is the address of the Prometheus metrics HTTP listener (e.g. ":9090").
+	// Empty disables the metrics endpoint.
+	MetricsListenAddr string `mapstructure:"metrics-listen-addr"`
+	// APIListenAddr is the address of the HTTP API serving /healthz, /readyz and /status.
+	// Empty disables the API.
+	APIListenAddr string `mapstructure:"api-listen-addr"`
+	// ProofAPI serves the SPV proofs of transactions on the API (/proof).
+	ProofAPI bool `mapstructure:"proof-api"`
+	// ProofMinConfirmations is the number of light client confirmations required to serve a
+	// proof. Defaults to confirmation_depth.
+	ProofMinConfirmations int64 `mapstructure:"proof-min-confirmations"`
+
+	// Coordination between relayer instances sharing the same light client.
+	// LeaseBackend is the lease storage: file|sqlite. Empty disables the coordination.
+	LeaseBackend string `mapstructure:"lease-backend"`
+	// LeasePath is the lease file or the SQLite database shared by the instances.
+	LeasePath string `mapstructure:"lease-path"`
+	// LeaseTTL is the lease duration. A standby takes over when the leader doesn't renew
+	// the lease within this period.
+	LeaseTTL time.Duration `mapstructure:"lease-ttl"`
+	// LeaseHolder identifies this instance. Defaults to hostname and pid.
+	LeaseHolder string `mapstructure:"lease-holder"`
 
 	// Walrus config
 	StoreBlocksInWalrus  bool     `mapstructure:"store-in-walrus"`
 	WalrusStorageEpochs  int      `mapstructure:"walrus-storage-epochs"`
 	WalrusPublisherURLs  []string `mapstructure:"walrus-publisher-urls"`
 	WalrusAggregatorURLs []string `mapstructure:"walrus-aggregator-urls"`
+	// WalrusVerifyUploads reads each stored block back from the aggregators to check it.
+	WalrusVerifyUploads bool `mapstructure:"walrus-verify-uploads"`
+	// WalrusUploadWorkers is the number of concurrent Walrus uploads. Defaults to
+	// DefaultWalrusUploadWorkers.
+	WalrusUploadWorkers int `mapstructure:"walrus-upload-workers"`
+	// WalrusRenewInterval is the period of the renewal of the Walrus blobs expiring soon.
+	// Zero disables the renewal.
+	WalrusRenewInterval time.Duration `mapstructure:"walrus-renew-interval"`
+	// WalrusRenewBeforeEpochs renews the blobs expiring within this number of Walrus epochs.
+	// Defaults to DefaultWalrusRenewBeforeEpochs.
+	WalrusRenewBeforeEpochs int64 `mapstructure:"walrus-renew-before-epochs"`
+	// WalrusRetentionBlocks is the number of most recent blocks whose blobs are renewed.
+	// Zero retains all the blocks.
+	WalrusRetentionBlocks int64 `mapstructure:"walrus-retention-blocks"`
+	// WalrusRenewBudget is the maximum FROST spent on renewals per Walrus epoch. Zero
+	// doesn't limit the spend. The limit is approximate: a renewal is started when its
+	// estimated cost fits in the budget left, and its actual cost may be higher.
+	WalrusRenewBudget int64 `mapstructure:"walrus-renew-budget"`
+	// WalrusEpochDuration is the duration of a Walrus epoch, used to estimate the current
+	// epoch between the epochs observed in the store responses. Zero uses the last observed
+	// epoch.
+	WalrusEpochDuration time.Duration `mapstructure:"walrus-epoch-duration"`
 }
 
 func isPresent(v string, list []string) bool {
@@ -75,6 +132,15 @@ func (cfg *RelayerConfig) Validate() error {
 	if err := cfg.validateBTCConfirmationDepth(); err != nil {
 		return err
 	}
+	if err := cfg.validateLease(); err != nil {
+		return err
+	}
+	if err := cfg.validateProofAPI(); err != nil {
+		return err
+	}
+	if err := cfg.validateWalrus(); err != nil {
+		return err
+	}
 	err := cfg.validateHeadersChunkSize()
 	return err
 }
@@ -122,6 +188,58 @@ func (cfg *RelayerConfig) validateBTCConfirmationDepth() error {
 	return nil
 }
 
+func (cfg *RelayerConfig) validateLease() error {
+	if cfg.LeaseBackend == "" {
+		return nil
+	}
+	validBackends := []string{"file", "sqlite"}
+	if !isPresent(cfg.LeaseBackend, validBackends) {
+		return fmt.Errorf("lease-backend %q is not one of %v", cfg.LeaseBackend, validBackends)
+	}
+	if cfg.LeasePath == "" {
+		return errors.New("lease-path is required when lease-backend is set")
+	}
+	if cfg.LeaseTTL <= 0 {
+		return errors.New("lease-ttl must be positive")
+	}
+	return nil
+}
+
+func (cfg *RelayerConfig) validateProofAPI() error {
+	if cfg.ProofAPI && cfg.APIListenAddr == "" {
+		return errors.New("api-listen-addr is required when proof-api is set")
+	}
+	if cfg.ProofMinConfirmations < 0 {
+		return errors.New("proof-min-confirmations can't be negative")
+	}
+	return nil
+}
+
+func (cfg *RelayerConfig) validateWalrus() error {
+	if cfg.WalrusUploadWorkers < 0 {
+		return errors.New("walrus-upload-workers can't be negative")
+	}
+	if cfg.WalrusRenewInterval < 0 {
+		return errors.New("walrus-renew-interval can't be negative")
+	}
+	if cfg.WalrusRenewInterval > 0 && cfg.StateDBFile == "" {
+		return errors.New("state-db-file is required when walrus-renew-interval is set")
+	}
+	if cfg.WalrusRenewBeforeEpochs < 0 {
+		return errors.New("walrus-renew-before-epochs can't be negative")
+	}
+	if cfg.WalrusRetentionBlocks < 0 {
+		return errors.New("walrus-retention-blocks can't be negative")
+	}
+	if cfg.WalrusRenewBudget < 0 {
+		return errors.New("walrus-renew-budget can't be negative")
+	}
+	if cfg.WalrusEpochDuration < 0 {
+		return errors.New("walrus-epoch-duration can't be negative")
+	}
+	return nil
+}
+
 func (cfg *RelayerConfig) validateHeadersChunkSize() error {
 	if cfg.HeadersChunkSize < minheadersChunkSize {
 		return fmt.Errorf("headers-chunk-size has to be at least %d", minheadersChunkSize)
@@ -141,6 +259,12 @@ func DefaultRelayerConfig() RelayerConfig {
 		HeadersChunkSize:      minheadersChunkSize,
 		BTCConfirmationDepth:  defaultConfirmationDepth,
 		IndexerURL:            "", // disabled by default
+		StateDBFile:           "", // disabled by default
+		MetricsListenAddr:     "", // disabled by default
+		APIListenAddr:         "", // disabled by default
+		ProofAPI:              false,
+		LeaseBackend:          "", // disabled by default
+		LeaseTTL:              defaultLeaseTTL,
 		StoreBlocksInWalrus:   false,
 		WalrusPublisherURLs:   []string{},
 		WalrusAggregatorURLs:  []string{},
@@ -1,10 +1,33 @@
 package config
 
+import "github.com/gonative-cc/relayer/signer"
+
 // SuiConfig holds configuration for interacting with the light client on Sui.
 type SuiConfig struct {
-	Endpoint    string `mapstructure:"endpoint"`
-	Mnemonic    string `mapstructure:"mnemonic"`
-	LCObjectID  string `mapstructure:"lc_object_id"`
-	LCPkgID     string `mapstructure:"lc_package_id"`
-	BTCLibPkgID string `mapstructure:"btc_lib_pkg_id"`
+	Endpoint string `mapstructure:"endpoint"`
+	// Mnemonic is deprecated: the key is stored in cleartext in the config file. Use Signer.
+	Mnemonic    string        `mapstructure:"mnemonic"`
+	Signer      signer.Config `mapstructure:"signer"`
+	LCObjectID  string        `mapstructure:"lc_object_id"`
+	LCPkgID     string        `mapstructure:"lc_package_id"`
+	BTCLibPkgID string        `mapstructure:"btc_lib_pkg_id"`
+	// DryRun makes the client dev-inspect the header transactions instead of executing them.
+	DryRun bool `mapstructure:"dry_run"`
+	// GasBudgetMargin is added to the gas cost estimated by a dry run, e.g. 0.2 for 20%.
+	GasBudgetMargin float64 `mapstructure:"gas_budget_margin"`
+	// MaxGasBudget caps the gas budget of a transaction, in MIST.
+	MaxGasBudget uint64 `mapstructure:"max_gas_budget"`
+	// LowBalanceThreshold is the SUI balance, in MIST, below which a warning is logged.
+	LowBalanceThreshold uint64 `mapstructure:"low_balance_threshold"`
+}
+
+// SignerConfig returns the signer config, falling back to the deprecated mnemonic when the
+// signer source is not set.
+func (c SuiConfig) SignerConfig() signer.Config {
+	cfg := c.Signer
+	if cfg.Source == "" && c.Mnemonic != "" {
+		cfg.Source = signer.SourceMnemonic
+		cfg.Mnemonic = c.Mnemonic
+	}
+	return cfg
 }
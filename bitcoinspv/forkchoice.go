@@ -0,0 +1,89 @@
+package bitcoinspv
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+// canBecomeBestChain checks if the branch ending with the given blocks has more work than
+// the light client best chain. The first block must extend a block known to the light client.
+// When the branch doesn't extend the light client head (competing forks), the branch work is
+// computed from the chain work of its parent in the light client plus the work of the headers.
+// Returns false when the light client is on a chain with at least as much work, in which case
+// submitting the branch would only waste gas.
+func (r *Relayer) canBecomeBestChain(ctx context.Context, blocks []*types.IndexedBlock) (bool, error) {
+	if len(blocks) == 0 {
+		return false, nil
+	}
+
+	var lcHead *clients.BlockInfo
+	err := RetryDo(r.logger, r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration, func() error {
+		var err error
+		lcHead, err = r.lcClient.GetLatestBlockInfo(ctx)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get light client head: %w", err)
+	}
+
+	parentHash := blocks[0].MsgBlock.Header.PrevBlock
+	if lcHead.Hash != nil && *lcHead.Hash == parentHash {
+		r.setLCOnHeavierChain(false)
+		return true, nil
+	}
+	if lcHead.ChainWork == nil {
+		r.logger.Debug().Msg("Light client head chain work unknown, skipping fork choice")
+		return true, nil
+	}
+
+	var parent *clients.BlockInfo
+	err = RetryDo(r.logger, r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration, func() error {
+		var err error
+		parent, err = r.lcClient.GetBlockInfo(ctx, parentHash)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get light client block %s: %w", parentHash, err)
+	}
+	if parent.ChainWork == nil {
+		r.logger.Debug().Msg("Fork point chain work unknown, skipping fork choice")
+		return true, nil
+	}
+
+	last := blocks[len(blocks)-1]
+	branchWork := new(big.Int).Add(parent.ChainWork, btctypes.HeadersWork(toBlockHeaders(blocks)))
+	logger := r.logger.With().
+		Int64("fork_height", parent.Height).
+		Int64("branch_tip_height", last.BlockHeight).
+		Str("branch_tip", last.BlockHash().String()).
+		Str("branch_work", branchWork.String()).
+		Int64("lc_head_height", lcHead.Height).
+		Str("lc_head_work", lcHead.ChainWork.String()).
+		Logger()
+
+	if branchWork.Cmp(lcHead.ChainWork) <= 0 {
+		r.setLCOnHeavierChain(true)
+		logger.Warn().Msg("Light client is on a chain with at least as much work as the node branch, skipping submission")
+		return false, nil
+	}
+
+	r.setLCOnHeavierChain(false)
+	logger.Info().Msg("Node branch has more work than the light client chain, submitting competing fork")
+	return true, nil
+}
+
+func (r *Relayer) setLCOnHeavierChain(v bool) {
+	r.state.setLCOnHeavierChain(v)
+	if v {
+		metrics.LCOnHeavierChain.Set(1)
+	} else {
+		metrics.LCOnHeavierChain.Set(0)
+	}
+}
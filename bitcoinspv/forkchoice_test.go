@@ -0,0 +1,110 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/mocks"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+// mockLCHead sets the light client head to the parent of the given block.
+func mockLCHead(ctx any, mockLC *mocks.MockBitcoinSPV, next *types.IndexedBlock) {
+	parent := next.MsgBlock.Header.PrevBlock
+	mockLC.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{Hash: &parent}, nil).Once()
+}
+
+func TestCanBecomeBestChain(t *testing.T) {
+	ctx := context.Background()
+	genesis := chaincfg.RegressionNetParams.GenesisBlock.Header
+	headers := btctypes.MineTestChain(t, genesis, 3) // regtest headers, work 2 each
+	blocks := make([]*types.IndexedBlock, len(headers))
+	for i := range headers {
+		blocks[i] = &types.IndexedBlock{BlockHeight: 101 + int64(i), MsgBlock: wire.NewMsgBlock(&headers[i])}
+	}
+	parent := genesis.BlockHash()
+	otherHead := chainhash.Hash{1}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mockLC *mocks.MockBitcoinSPV)
+		expected    bool
+		expectedErr bool
+	}{
+		{
+			name: "branch extends light client head",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLCHead(ctx, mockLC, blocks[0])
+			},
+			expected: true,
+		},
+		{
+			name: "competing branch with more work",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{
+					Hash: &otherHead, Height: 102, ChainWork: big.NewInt(1005),
+				}, nil).Once()
+				mockLC.On("GetBlockInfo", ctx, parent).Return(&clients.BlockInfo{
+					Hash: &parent, Height: 100, ChainWork: big.NewInt(1000),
+				}, nil).Once()
+			},
+			expected: true, // 1000 + 3*2 > 1005
+		},
+		{
+			name: "competing branch with the same work",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{
+					Hash: &otherHead, Height: 103, ChainWork: big.NewInt(1006),
+				}, nil).Once()
+				mockLC.On("GetBlockInfo", ctx, parent).Return(&clients.BlockInfo{
+					Hash: &parent, Height: 100, ChainWork: big.NewInt(1000),
+				}, nil).Once()
+			},
+			expected: false,
+		},
+		{
+			name: "light client head chain work unknown",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{Hash: &otherHead}, nil).Once()
+			},
+			expected: true,
+		},
+		{
+			name: "fork point not found",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{
+					Hash: &otherHead, Height: 102, ChainWork: big.NewInt(1005),
+				}, nil).Once()
+				mockLC.On("GetBlockInfo", ctx, parent).Return(nil, errors.New("block not found"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLC := mocks.NewMockBitcoinSPV(t)
+			tt.mockSetup(mockLC)
+			r := &Relayer{lcClient: mockLC, logger: zerolog.Nop(), Config: testSubmitConfig}
+
+			ok, err := r.canBecomeBestChain(ctx, blocks)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+			assert.Equal(t, !tt.expected, r.state.lcHeavier)
+		})
+	}
+}
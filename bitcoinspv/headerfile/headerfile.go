@@ -0,0 +1,161 @@
+// Package headerfile reads and writes files of Bitcoin block headers with their heights,
+// used to export headers from a node and replay them to the light client offline.
+//
+// Two formats are supported:
+//   - raw: consecutive 84 byte records, the height as a little-endian uint32 followed by
+//     the 80 byte serialized header,
+//   - hex: one "<height> <hex encoded header>" line per header.
+package headerfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+)
+
+// Supported file formats.
+const (
+	FormatRaw = "raw"
+	FormatHex = "hex"
+)
+
+const headerSize = wire.MaxBlockHeaderPayload
+
+var (
+	// ErrUnknownFormat is returned for formats other than FormatRaw and FormatHex.
+	ErrUnknownFormat = errors.New("unknown header file format")
+	// ErrNotConsecutive is returned when the headers of a file don't form a chain.
+	ErrNotConsecutive = errors.New("headers are not consecutive")
+)
+
+// Writer writes headers to a header file.
+type Writer struct {
+	w      *bufio.Writer
+	format string
+}
+
+// NewWriter creates a Writer in the given format. Flush must be called after the last header.
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	if format != FormatRaw && format != FormatHex {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	return &Writer{w: bufio.NewWriter(w), format: format}, nil
+}
+
+// Write writes a header at the given height.
+func (w *Writer) Write(height int64, header *wire.BlockHeader) error {
+	if height < 0 || height > math.MaxUint32 {
+		return fmt.Errorf("height %d out of range", height)
+	}
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		return err
+	}
+
+	if w.format == FormatHex {
+		_, err := fmt.Fprintf(w.w, "%d %s\n", height, hex.EncodeToString(buf.Bytes()))
+		return err
+	}
+	var h [4]byte
+	binary.LittleEndian.PutUint32(h[:], uint32(height))
+	if _, err := w.w.Write(h[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
+
+// Flush writes the buffered headers to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Read reads all the headers of a file in the given format. The headers must be consecutive:
+// each header is at the height following the previous one and extends it.
+func Read(r io.Reader, format string) ([]*types.IndexedBlock, error) {
+	var blocks []*types.IndexedBlock
+	var err error
+	switch format {
+	case FormatRaw:
+		blocks, err = readRaw(r)
+	case FormatHex:
+		blocks, err = readHex(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i < len(blocks); i++ {
+		prev, b := blocks[i-1], blocks[i]
+		if b.BlockHeight != prev.BlockHeight+1 || b.MsgBlock.Header.PrevBlock != prev.BlockHash() {
+			return nil, fmt.Errorf("%w: header %s at height %d doesn't extend %s at height %d",
+				ErrNotConsecutive, b.BlockHash(), b.BlockHeight, prev.BlockHash(), prev.BlockHeight)
+		}
+	}
+	return blocks, nil
+}
+
+func readRaw(r io.Reader) ([]*types.IndexedBlock, error) {
+	br := bufio.NewReader(r)
+	var blocks []*types.IndexedBlock
+	var h [4]byte
+	for {
+		if _, err := io.ReadFull(br, h[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return blocks, nil
+			}
+			return nil, fmt.Errorf("record %d: %w", len(blocks), err)
+		}
+		var header wire.BlockHeader
+		if err := header.Deserialize(br); err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(blocks), err)
+		}
+		height := int64(binary.LittleEndian.Uint32(h[:]))
+		blocks = append(blocks, types.NewIndexedBlock(height, wire.NewMsgBlock(&header)))
+	}
+}
+
+func readHex(r io.Reader) ([]*types.IndexedBlock, error) {
+	scanner := bufio.NewScanner(r)
+	var blocks []*types.IndexedBlock
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<height> <header>\"", line)
+		}
+		height, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid height: %w", line, err)
+		}
+		raw, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid header: %w", line, err)
+		}
+		if len(raw) != headerSize {
+			return nil, fmt.Errorf("line %d: header is %d bytes, expected %d", line, len(raw), headerSize)
+		}
+		var header wire.BlockHeader
+		if err := header.Deserialize(bytes.NewReader(raw)); err != nil {
+			return nil, fmt.Errorf("line %d: invalid header: %w", line, err)
+		}
+		blocks = append(blocks, types.NewIndexedBlock(height, wire.NewMsgBlock(&header)))
+	}
+	return blocks, scanner.Err()
+}
@@ -0,0 +1,64 @@
+package headerfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+func TestRoundTrip(t *testing.T) {
+	headers := btctypes.MineTestChain(t, chaincfg.RegressionNetParams.GenesisBlock.Header, 3)
+	for _, format := range []string{FormatRaw, FormatHex} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, format)
+			require.NoError(t, err)
+			for i := range headers {
+				require.NoError(t, w.Write(int64(10+i), &headers[i]))
+			}
+			require.NoError(t, w.Flush())
+			if format == FormatRaw {
+				assert.Equal(t, 3*84, buf.Len())
+			}
+
+			blocks, err := Read(&buf, format)
+			require.NoError(t, err)
+			require.Len(t, blocks, 3)
+			for i, b := range blocks {
+				assert.Equal(t, int64(10+i), b.BlockHeight)
+				assert.Equal(t, headers[i], b.MsgBlock.Header)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	headers := btctypes.MineTestChain(t, chaincfg.RegressionNetParams.GenesisBlock.Header, 3)
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatHex)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(1, &headers[0]))
+	require.NoError(t, w.Write(2, &headers[2]))
+	require.NoError(t, w.Flush())
+
+	_, err = Read(bytes.NewReader(buf.Bytes()), FormatHex)
+	assert.ErrorIs(t, err, ErrNotConsecutive)
+
+	_, err = Read(strings.NewReader("1 00ff\n"), FormatHex)
+	assert.ErrorContains(t, err, "line 1")
+
+	// truncated raw record
+	_, err = Read(bytes.NewReader(make([]byte, 50)), FormatRaw)
+	assert.Error(t, err)
+
+	_, err = Read(&buf, "csv")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	_, err = NewWriter(&buf, "csv")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,49 @@
+package bitcoinspv
+
+import (
+	"context"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+)
+
+// LeaderElector decides which of the relayer instances sharing the light client submits
+// headers. It is implemented by lease.Elector.
+type LeaderElector interface {
+	// IsLeader returns true when this instance must submit headers.
+	IsLeader() bool
+	// Elected is notified every time this instance becomes the leader.
+	Elected() <-chan struct{}
+}
+
+// SetLeaderElector enables the coordination with other relayer instances: headers are
+// submitted only while the elector reports this instance as the leader. Standbys keep
+// following the Bitcoin node and their cache warm, so they can take over right away.
+// When the elector is nil (default), the relayer always submits.
+func (r *Relayer) SetLeaderElector(e LeaderElector) {
+	r.elector = e
+}
+
+func (r *Relayer) isLeader() bool {
+	return r.elector == nil || r.elector.IsLeader()
+}
+
+// electedChan returns the channel notified when this instance becomes the leader.
+// Returns nil (blocks forever in select) when coordination is disabled.
+func (r *Relayer) electedChan() <-chan struct{} {
+	if r.elector == nil {
+		return nil
+	}
+	return r.elector.Elected()
+}
+
+// onElected submits the cached headers the previous leader may not have submitted.
+func (r *Relayer) onElected() {
+	r.logger.Info().Msg("Elected as leader, submitting cached headers")
+	ctx, cancel := context.WithTimeout(context.Background(), r.Config.ProcessBlockTimeout)
+	defer cancel()
+	if err := r.processHeaders(ctx); err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to submit cached headers after election, restarting bootstrap")
+		metrics.BootstrapRestarts.Inc()
+		r.multitryBootstrap(true)
+	}
+}
@@ -0,0 +1,52 @@
+package bitcoinspv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/mocks"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testElector struct {
+	leader  bool
+	elected chan struct{}
+}
+
+func (e *testElector) IsLeader() bool { return e.leader }
+
+func (e *testElector) Elected() <-chan struct{} { return e.elected }
+
+func TestProcessHeadersStandby(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 3, 100)
+
+	// no light client calls in standby
+	mockLC := mocks.NewMockBitcoinSPV(t)
+	r := &Relayer{lcClient: mockLC, logger: zerolog.Nop(), Config: testSubmitConfig}
+	r.SetLeaderElector(&testElector{leader: false})
+
+	count, err := r.ProcessHeaders(ctx, testBlocks)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestOnElected(t *testing.T) {
+	testBlocks := types.CreateTestIndexedBlocks(t, 3, 100)
+	cache, err := types.NewBTCCache(10)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Init(testBlocks))
+
+	mockLC := mocks.NewMockBitcoinSPV(t)
+	// the previous leader submitted only the first block
+	mockLC.On("ContainsBlocks", mock.Anything, mock.Anything).Return(knownPrefix(testBlocks, 1)).Once()
+	mockLCHead(mock.Anything, mockLC, testBlocks[1])
+	mockLC.On("InsertHeaders", mock.Anything, toBlockHeaders(testBlocks[1:])).Return("digest", nil).Once()
+
+	r := &Relayer{lcClient: mockLC, btcCache: cache, logger: zerolog.Nop(), Config: testSubmitConfig}
+	r.SetLeaderElector(&testElector{leader: true})
+	r.onElected()
+}
@@ -0,0 +1,102 @@
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// FileBackend keeps the lease in a file, e.g. on a volume shared by the relayer instances.
+// Reads and writes are guarded by an exclusive flock. Expiry is checked with the local
+// clock, so the hosts clocks must be synchronized.
+type FileBackend struct {
+	path string
+	now  func() time.Time
+}
+
+var _ Backend = &FileBackend{}
+
+type fileLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFileBackend creates a FileBackend storing the lease at path.
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path, now: time.Now}
+}
+
+// TryAcquire implements Backend.
+func (b *FileBackend) TryAcquire(_ context.Context, holder string, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := b.withLock(func(f *os.File, current fileLease) error {
+		now := b.now()
+		if current.Holder != "" && current.Holder != holder && now.Before(current.ExpiresAt) {
+			return nil
+		}
+		acquired = true
+		return writeLease(f, fileLease{Holder: holder, ExpiresAt: now.Add(ttl)})
+	})
+	return acquired, err
+}
+
+// Release implements Backend.
+func (b *FileBackend) Release(_ context.Context, holder string) error {
+	return b.withLock(func(f *os.File, current fileLease) error {
+		if current.Holder != holder {
+			return nil
+		}
+		return f.Truncate(0)
+	})
+}
+
+// Close implements Backend.
+func (b *FileBackend) Close() error {
+	return nil
+}
+
+// withLock opens the lease file, locks it and calls fn with the current lease.
+func (b *FileBackend) withLock(fn func(f *os.File, current fileLease) error) error {
+	f, err := os.OpenFile(b.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("lease: can't open file: %w", err)
+	}
+	defer f.Close()
+
+	// #nosec G115 -- file descriptors fit in int
+	fd := int(f.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("lease: can't lock file: %w", err)
+	}
+	defer syscall.Flock(fd, syscall.LOCK_UN) //nolint:errcheck
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("lease: can't read file: %w", err)
+	}
+	var current fileLease
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &current); err != nil {
+			return fmt.Errorf("lease: invalid file content: %w", err)
+		}
+	}
+	return fn(f, current)
+}
+
+func writeLease(f *os.File, l fileLease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,158 @@
+// Package lease implements leader election between bitcoin-spv relayer instances sharing
+// the same light client. Only the instance holding the lease submits headers, the others
+// stay in standby and take over when the lease expires.
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/rs/zerolog"
+)
+
+// Supported lease backends
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
+
+// ErrInvalidTTL is returned when the lease TTL is not positive.
+var ErrInvalidTTL = errors.New("lease ttl must be positive")
+
+// Backend stores the lease shared by the relayer instances.
+type Backend interface {
+	// TryAcquire acquires the lease for holder, or renews it if holder already owns it,
+	// until now+ttl. Returns false when another holder owns a lease that didn't expire.
+	TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if it is owned by holder.
+	Release(ctx context.Context, holder string) error
+	// Close releases the backend resources.
+	Close() error
+}
+
+// NewBackend creates the lease backend of the given kind stored at path.
+func NewBackend(kind, path string) (Backend, error) {
+	switch kind {
+	case BackendFile:
+		return NewFileBackend(path), nil
+	case BackendSQLite:
+		return NewSQLiteBackend(path)
+	default:
+		return nil, fmt.Errorf("unknown lease backend %q", kind)
+	}
+}
+
+// DefaultHolder returns an identifier of the current process: hostname and pid.
+func DefaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Elector keeps trying to acquire the lease and renews it while it's the leader.
+// The lease is renewed every ttl/3, so a standby takes over at most ttl (plus one
+// renew interval) after the leader stops renewing.
+type Elector struct {
+	backend Backend
+	holder  string
+	ttl     time.Duration
+	logger  zerolog.Logger
+
+	isLeader atomic.Bool
+	elected  chan struct{}
+}
+
+// NewElector creates an Elector for the given holder.
+func NewElector(backend Backend, holder string, ttl time.Duration, parentLogger zerolog.Logger) (*Elector, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	return &Elector{
+		backend: backend,
+		holder:  holder,
+		ttl:     ttl,
+		logger:  parentLogger.With().Str("module", "lease").Str("holder", holder).Logger(),
+		elected: make(chan struct{}, 1),
+	}, nil
+}
+
+// IsLeader returns true when the elector owns the lease.
+func (e *Elector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// Elected returns a channel notified every time the elector becomes the leader.
+func (e *Elector) Elected() <-chan struct{} {
+	return e.elected
+}
+
+// Holder returns the identifier used to acquire the lease.
+func (e *Elector) Holder() string {
+	return e.holder
+}
+
+// Run acquires and renews the lease until ctx is done, then releases it.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	acquired, err := e.backend.TryAcquire(ctx, e.holder, e.ttl)
+	if err != nil {
+		// we can't tell if the lease is still ours, so stop submitting to be safe
+		e.logger.Err(err).Msg("Failed to acquire the lease")
+		acquired = false
+	}
+	e.setLeader(acquired)
+}
+
+func (e *Elector) setLeader(leader bool) {
+	if e.isLeader.Swap(leader) == leader {
+		return
+	}
+	if !leader {
+		metrics.IsLeader.Set(0)
+		e.logger.Warn().Msg("Lost the lease, switching to standby")
+		return
+	}
+	metrics.IsLeader.Set(1)
+	e.logger.Info().Msg("Acquired the lease, this instance is now the leader")
+	select {
+	case e.elected <- struct{}{}:
+	default:
+	}
+}
+
+func (e *Elector) release() {
+	if !e.isLeader.Swap(false) {
+		return
+	}
+	metrics.IsLeader.Set(0)
+	// ctx is already canceled, give the release a short timeout on its own
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.backend.Release(ctx, e.holder); err != nil {
+		e.logger.Err(err).Msg("Failed to release the lease")
+		return
+	}
+	e.logger.Info().Msg("Lease released")
+}
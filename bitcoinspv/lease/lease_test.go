@@ -0,0 +1,168 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a manually advanced time source.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestBackends(t *testing.T, c *clock) map[string]Backend {
+	t.Helper()
+	dir := t.TempDir()
+
+	file := NewFileBackend(filepath.Join(dir, "lease.json"))
+	file.now = c.now
+
+	sqlite, err := NewSQLiteBackend(filepath.Join(dir, "lease.db"))
+	assert.NoError(t, err)
+	sqlite.now = c.now
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]Backend{BackendFile: file, BackendSQLite: sqlite}
+}
+
+func TestBackends(t *testing.T) {
+	ctx := context.Background()
+	const ttl = 10 * time.Second
+	c := &clock{t: time.Unix(1_700_000_000, 0)}
+
+	for name, b := range newTestBackends(t, c) {
+		t.Run(name, func(t *testing.T) {
+			ok, err := b.TryAcquire(ctx, "a", ttl)
+			assert.NoError(t, err)
+			assert.True(t, ok, "free lease")
+
+			ok, err = b.TryAcquire(ctx, "b", ttl)
+			assert.NoError(t, err)
+			assert.False(t, ok, "lease owned by a")
+
+			c.t = c.t.Add(ttl / 2)
+			ok, err = b.TryAcquire(ctx, "a", ttl)
+			assert.NoError(t, err)
+			assert.True(t, ok, "renew")
+
+			// the renewal moved the expiry
+			c.t = c.t.Add(ttl / 2)
+			ok, err = b.TryAcquire(ctx, "b", ttl)
+			assert.NoError(t, err)
+			assert.False(t, ok, "renewed lease owned by a")
+
+			c.t = c.t.Add(ttl)
+			ok, err = b.TryAcquire(ctx, "b", ttl)
+			assert.NoError(t, err)
+			assert.True(t, ok, "expired lease taken over")
+
+			// release by a non-owner is a no-op
+			assert.NoError(t, b.Release(ctx, "a"))
+			ok, err = b.TryAcquire(ctx, "a", ttl)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.NoError(t, b.Release(ctx, "b"))
+			ok, err = b.TryAcquire(ctx, "a", ttl)
+			assert.NoError(t, err)
+			assert.True(t, ok, "released lease")
+		})
+	}
+}
+
+// fakeBackend returns the queued results of TryAcquire.
+type fakeBackend struct {
+	mu       sync.Mutex
+	results  []error // nil means acquired, errNotAcquired means owned by another holder
+	released bool
+}
+
+var errNotAcquired = errors.New("not acquired")
+
+func (b *fakeBackend) TryAcquire(context.Context, string, time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.results) == 0 {
+		return true, nil
+	}
+	res := b.results[0]
+	b.results = b.results[1:]
+	if errors.Is(res, errNotAcquired) {
+		return false, nil
+	}
+	return res == nil, res
+}
+
+func (b *fakeBackend) Release(context.Context, string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.released = true
+	return nil
+}
+
+func (b *fakeBackend) Close() error { return nil }
+
+func TestElector(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeBackend{results: []error{errNotAcquired, nil, errors.New("db locked"), nil}}
+	e, err := NewElector(backend, "a", time.Second, zerolog.Nop())
+	assert.NoError(t, err)
+
+	e.tick(ctx)
+	assert.False(t, e.IsLeader())
+	assert.Empty(t, e.Elected())
+
+	e.tick(ctx)
+	assert.True(t, e.IsLeader())
+	assert.Len(t, e.Elected(), 1)
+	<-e.Elected()
+
+	// backend errors drop the leadership
+	e.tick(ctx)
+	assert.False(t, e.IsLeader())
+
+	e.tick(ctx)
+	assert.True(t, e.IsLeader())
+	assert.Len(t, e.Elected(), 1)
+
+	e.release()
+	assert.False(t, e.IsLeader())
+	assert.True(t, backend.released)
+}
+
+func TestElectorRun(t *testing.T) {
+	backend := &fakeBackend{}
+	e, err := NewElector(backend, "a", 30*time.Millisecond, zerolog.Nop())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-e.Elected():
+	case <-time.After(time.Second):
+		t.Fatal("elector not elected")
+	}
+	assert.True(t, e.IsLeader())
+
+	cancel()
+	<-done
+	assert.False(t, e.IsLeader())
+	assert.True(t, backend.released)
+}
+
+func TestNewElectorInvalidTTL(t *testing.T) {
+	_, err := NewElector(&fakeBackend{}, "a", 0, zerolog.Nop())
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+}
@@ -0,0 +1,75 @@
+package lease
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // Import the SQLite driver
+)
+
+// leaseName is the row used by the bitcoin-spv relayers.
+const leaseName = "bitcoin-spv"
+
+const createLeasesTable = `CREATE TABLE IF NOT EXISTS leases (
+    name TEXT NOT NULL PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at INTEGER NOT NULL
+)`
+
+// The row is updated only when it's owned by the holder or expired, otherwise no row
+// is changed.
+const acquireLease = `INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE leases.holder = excluded.holder OR leases.expires_at <= ?`
+
+const releaseLease = `DELETE FROM leases WHERE name = ? AND holder = ?`
+
+// SQLiteBackend keeps the lease in a row of a SQLite database shared by the relayer instances.
+// Expiry is checked with the local clock, so the hosts clocks must be synchronized.
+type SQLiteBackend struct {
+	conn *sql.DB
+	now  func() time.Time
+}
+
+var _ Backend = &SQLiteBackend{}
+
+// NewSQLiteBackend opens the SQLite database at path and creates the leases table.
+func NewSQLiteBackend(path string) (*SQLiteBackend, error) {
+	conn, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("lease: can't open sqlite3: %w", err)
+	}
+	if _, err := conn.Exec(createLeasesTable); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("lease: can't create leases table: %w", err)
+	}
+	return &SQLiteBackend{conn: conn, now: time.Now}, nil
+}
+
+// TryAcquire implements Backend.
+func (b *SQLiteBackend) TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	now := b.now()
+	res, err := b.conn.ExecContext(ctx, acquireLease,
+		leaseName, holder, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("lease: can't acquire: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Release implements Backend.
+func (b *SQLiteBackend) Release(ctx context.Context, holder string) error {
+	_, err := b.conn.ExecContext(ctx, releaseLease, leaseName, holder)
+	return err
+}
+
+// Close implements Backend.
+func (b *SQLiteBackend) Close() error {
+	return b.conn.Close()
+}
@@ -0,0 +1,210 @@
+// Package metrics defines the Prometheus collectors exposed by the bitcoin-spv relayer.
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "bitcoin_spv"
+
+// Registry holds all the relayer collectors together with the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// BTCTipHeight is the height of the best block known to the Bitcoin node.
+	BTCTipHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "btc_tip_height",
+		Help:      "Height of the best block known to the Bitcoin node.",
+	})
+	// LCTipHeight is the height of the best block known to the light client.
+	LCTipHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "lc_tip_height",
+		Help:      "Height of the best block known to the light client.",
+	})
+	// LCLag is the number of blocks the light client is behind the Bitcoin node.
+	LCLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "lc_lag_blocks",
+		Help:      "Number of blocks the light client is behind the Bitcoin node.",
+	})
+	// LCOnHeavierChain is 1 when the light client is on a chain with at least as much work
+	// as the Bitcoin node branch.
+	LCOnHeavierChain = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "lc_on_heavier_chain",
+		Help:      "1 when the light client is on a chain with at least as much work as the Bitcoin node branch.",
+	})
+	// IsLeader is 1 when this instance holds the lease and submits headers. Only updated
+	// when the coordination between relayer instances is enabled.
+	IsLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "is_leader",
+		Help:      "1 when this instance holds the lease and submits headers.",
+	})
+	// SuiGasBalance is the SUI balance of the relayer account, in MIST, observed when paying
+	// the gas of a transaction.
+	SuiGasBalance = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sui_gas_balance_mist",
+		Help:      "SUI balance of the relayer account, in MIST.",
+	})
+	// WalrusUploadQueueSize is the number of blocks waiting to be stored in Walrus.
+	WalrusUploadQueueSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "walrus_upload_queue_size",
+		Help:      "Number of blocks waiting to be stored in Walrus.",
+	})
+	// WalrusBlobsExpiring is the number of retained Walrus blobs expiring within
+	// walrus-renew-before-epochs, observed at each renewal round.
+	WalrusBlobsExpiring = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "walrus_blobs_expiring",
+		Help:      "Number of retained Walrus blobs due for renewal.",
+	})
+
+	// HeadersSubmitted counts headers successfully inserted to the light client.
+	HeadersSubmitted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "headers_submitted_total",
+		Help:      "Number of headers successfully inserted to the light client.",
+	})
+	// ChunksFailed counts header chunks that could not be inserted after all retries.
+	ChunksFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chunks_failed_total",
+		Help:      "Number of header chunks that failed to be inserted after all retries.",
+	})
+	// ReorgsDetected counts Bitcoin reorgs detected from block events.
+	ReorgsDetected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reorgs_detected_total",
+		Help:      "Number of Bitcoin reorgs detected from block events.",
+	})
+	// ReorgDepth observes the number of cache blocks rolled back by a reorg.
+	ReorgDepth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reorg_depth_blocks",
+		Help:      "Number of blocks rolled back by a Bitcoin reorg.",
+		Buckets:   []float64{1, 2, 3, 4, 6, 10, 20, 50},
+	})
+	// BootstrapRestarts counts bootstraps triggered by block event processing errors.
+	BootstrapRestarts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bootstrap_restarts_total",
+		Help:      "Number of bootstraps triggered by block event processing errors.",
+	})
+	// RetryFailures counts the failed attempts inside RetryDo, labeled by error category. Only
+	// the retryable failures are retried.
+	RetryFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retry_failures_total",
+		Help:      "Number of failed attempts handled by RetryDo, by error category.",
+	}, []string{"category"})
+
+	// WalrusVerifyFailures counts Walrus blobs that could not be read back or didn't hold
+	// the stored block.
+	WalrusVerifyFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "walrus_verify_failures_total",
+		Help:      "Number of Walrus blobs that could not be read back or didn't match the stored block.",
+	})
+
+	// WalrusUploadRetries counts the failed Walrus upload attempts, retried with backoff.
+	WalrusUploadRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "walrus_upload_retries_total",
+		Help:      "Number of failed Walrus upload attempts, retried with backoff.",
+	})
+	// WalrusBlobsRenewed counts the Walrus blobs whose storage was extended.
+	WalrusBlobsRenewed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "walrus_blobs_renewed_total",
+		Help:      "Number of Walrus blobs whose storage was extended.",
+	})
+	// WalrusRenewCost counts the FROST spent extending Walrus storage.
+	WalrusRenewCost = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "walrus_renew_cost_frost_total",
+		Help:      "FROST spent extending the storage of Walrus blobs.",
+	})
+
+	// InsertHeadersDuration observes the latency of InsertHeaders calls.
+	InsertHeadersDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "insert_headers_duration_seconds",
+		Help:      "Latency of InsertHeaders calls to the light client.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	})
+	// IndexerUploadDuration observes the latency of sending blocks to the indexer.
+	IndexerUploadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "indexer_upload_duration_seconds",
+		Help:      "Latency of sending blocks to the nBTC indexer.",
+		Buckets:   prometheus.DefBuckets,
+	})
+	// WalrusUploadDuration observes the latency of storing blocks in Walrus.
+	WalrusUploadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "walrus_upload_duration_seconds",
+		Help:      "Latency of storing blocks in Walrus.",
+		Buckets:   []float64{0.5, 1, 2, 4, 8, 16, 32, 64},
+	})
+)
+
+// last observed tips, used to compute the lag
+var btcTip, lcTip atomic.Int64
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		BTCTipHeight,
+		LCTipHeight,
+		LCLag,
+		LCOnHeavierChain,
+		IsLeader,
+		SuiGasBalance,
+		WalrusUploadQueueSize,
+		WalrusBlobsExpiring,
+		HeadersSubmitted,
+		ChunksFailed,
+		ReorgsDetected,
+		ReorgDepth,
+		BootstrapRestarts,
+		RetryFailures,
+		WalrusVerifyFailures,
+		WalrusUploadRetries,
+		WalrusBlobsRenewed,
+		WalrusRenewCost,
+		InsertHeadersDuration,
+		IndexerUploadDuration,
+		WalrusUploadDuration,
+	)
+}
+
+// SetBTCTip records the Bitcoin node tip height and updates the light client lag.
+func SetBTCTip(height int64) {
+	btcTip.Store(height)
+	BTCTipHeight.Set(float64(height))
+	updateLag()
+}
+
+// SetLCTip records the light client tip height and updates the light client lag.
+func SetLCTip(height int64) {
+	lcTip.Store(height)
+	LCTipHeight.Set(float64(height))
+	updateLag()
+}
+
+func updateLag() {
+	btc, lc := btcTip.Load(), lcTip.Load()
+	if btc == 0 || lc == 0 {
+		return
+	}
+	LCLag.Set(float64(btc - lc))
+}
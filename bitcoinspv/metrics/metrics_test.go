@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLag(t *testing.T) {
+	SetBTCTip(0)
+	SetLCTip(0)
+	LCLag.Set(0)
+
+	SetBTCTip(110)
+	assert.Equal(t, float64(0), testutil.ToFloat64(LCLag), "lag is unknown until both tips are set")
+
+	SetLCTip(100)
+	assert.Equal(t, float64(10), testutil.ToFloat64(LCLag))
+	assert.Equal(t, float64(100), testutil.ToFloat64(LCTipHeight))
+
+	SetBTCTip(112)
+	assert.Equal(t, float64(12), testutil.ToFloat64(LCLag))
+	assert.Equal(t, float64(112), testutil.ToFloat64(BTCTipHeight))
+}
@@ -0,0 +1,108 @@
+package bitcoinspv
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Proof errors
+var (
+	ErrBlockNotInLC           = errors.New("block not known to the light client")
+	ErrNotEnoughConfirmations = errors.New("not enough confirmations")
+	ErrProofRejected          = errors.New("proof rejected by the light client")
+)
+
+// TxProof is the SPV proof of a transaction included in a block of the light client chain.
+// Hashes are hex encoded in the byte order of the Bitcoin RPC, e.g. the displayed txid.
+type TxProof struct {
+	TxID      string `json:"txid"`
+	BlockHash string `json:"block_hash"`
+	// Header is the hex encoded 80 bytes block header.
+	Header  string `json:"header"`
+	Height  int64  `json:"height"`
+	TxIndex uint32 `json:"tx_index"`
+	// Proof is the merkle branch, from the transactions level up to the root.
+	Proof []string `json:"proof"`
+	// Confirmations is the number of blocks of the light client chain from the block up to
+	// the light client tip, the block included.
+	Confirmations int64 `json:"confirmations"`
+}
+
+// TxProof builds the SPV proof of the transaction txID. The block including the transaction
+// is looked up with the Bitcoin node when blockHash is nil. The block must be in the light
+// client chain with at least proof-min-confirmations confirmations, and the proof is checked
+// with the light client before it's returned.
+func (r *Relayer) TxProof(ctx context.Context, blockHash *chainhash.Hash, txID chainhash.Hash) (*TxProof, error) {
+	if blockHash == nil {
+		var err error
+		if blockHash, err = r.btcClient.GetBTCTxBlockHash(&txID); err != nil {
+			return nil, err
+		}
+	}
+	block, err := r.btcClient.GetBTCBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	merkleProof, err := block.MerkleProof(txID)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := r.lcClient.ContainsBlock(ctx, *blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block %s in light client: %w", blockHash, err)
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotInLC, blockHash)
+	}
+	tip, err := r.lcClient.GetLatestBlockInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get light client tip: %w", err)
+	}
+	confirmations := tip.Height - block.BlockHeight + 1
+	if minConfirmations := r.proofMinConfirmations(); confirmations < minConfirmations {
+		return nil, fmt.Errorf("%w: block %s has %d confirmations, %d required",
+			ErrNotEnoughConfirmations, blockHash, confirmations, minConfirmations)
+	}
+	// the block may be on a fork known to the light client: verify_tx only accepts the
+	// blocks of the light client chain
+	valid, err := r.lcClient.VerifyTx(ctx, block.BlockHeight, merkleProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify tx %s with light client: %w", txID, err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("%w: tx %s, block %s", ErrProofRejected, txID, blockHash)
+	}
+
+	var header bytes.Buffer
+	if err := block.MsgBlock.Header.Serialize(&header); err != nil {
+		return nil, err
+	}
+	branch := make([]string, len(merkleProof.Branch))
+	for i := range merkleProof.Branch {
+		branch[i] = merkleProof.Branch[i].String()
+	}
+	return &TxProof{
+		TxID:          txID.String(),
+		BlockHash:     blockHash.String(),
+		Header:        hex.EncodeToString(header.Bytes()),
+		Height:        block.BlockHeight,
+		TxIndex:       merkleProof.TxIndex,
+		Proof:         branch,
+		Confirmations: confirmations,
+	}, nil
+}
+
+// proofMinConfirmations returns the confirmations required by TxProof, the relayer
+// confirmation depth by default.
+func (r *Relayer) proofMinConfirmations() int64 {
+	if r.Config.ProofMinConfirmations > 0 {
+		return r.Config.ProofMinConfirmations
+	}
+	return r.btcConfirmationDepth
+}
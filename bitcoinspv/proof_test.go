@@ -0,0 +1,107 @@
+package bitcoinspv
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testProofBlock(height int64) *types.IndexedBlock {
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{Version: 1})
+	for i := 0; i < 3; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		// #nosec G115
+		tx.LockTime = uint32(i)
+		_ = msgBlock.AddTransaction(tx)
+	}
+	return types.NewIndexedBlock(height, msgBlock)
+}
+
+func TestTxProof(t *testing.T) {
+	ctx := context.Background()
+	block := testProofBlock(100)
+	blockHash := block.BlockHash()
+	txID := block.MsgBlock.Transactions[1].TxHash()
+	tipAt := func(height int64) *clients.BlockInfo {
+		return &clients.BlockInfo{Hash: &chainhash.Hash{}, Height: height}
+	}
+
+	t.Run("proof", func(t *testing.T) {
+		relayer, btcClient, lcClient := setupTest(t)
+		btcClient.On("GetBTCTxBlockHash", &txID).Return(&blockHash, nil).Once()
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+		lcClient.On("ContainsBlock", ctx, blockHash).Return(true, nil).Once()
+		lcClient.On("GetLatestBlockInfo", ctx).Return(tipAt(107), nil).Once()
+		lcClient.On("VerifyTx", ctx, int64(100), mock.MatchedBy(func(p *types.MerkleProof) bool {
+			return p.TxID == txID && p.TxIndex == 1 && len(p.Branch) == 2
+		})).Return(true, nil).Once()
+
+		proof, err := relayer.TxProof(ctx, nil, txID)
+		require.NoError(t, err)
+		var header bytes.Buffer
+		require.NoError(t, block.MsgBlock.Header.Serialize(&header))
+		assert.Equal(t, hex.EncodeToString(header.Bytes()), proof.Header)
+		assert.Equal(t, txID.String(), proof.TxID)
+		assert.Equal(t, blockHash.String(), proof.BlockHash)
+		assert.Equal(t, int64(100), proof.Height)
+		assert.Equal(t, int64(8), proof.Confirmations)
+		assert.Equal(t, uint32(1), proof.TxIndex)
+		require.Len(t, proof.Proof, 2)
+		assert.Equal(t, block.MsgBlock.Transactions[0].TxHash().String(), proof.Proof[0])
+	})
+
+	t.Run("block not in light client", func(t *testing.T) {
+		relayer, btcClient, lcClient := setupTest(t)
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+		lcClient.On("ContainsBlock", ctx, blockHash).Return(false, nil).Once()
+
+		_, err := relayer.TxProof(ctx, &blockHash, txID)
+		assert.ErrorIs(t, err, ErrBlockNotInLC)
+	})
+
+	t.Run("not enough confirmations", func(t *testing.T) {
+		relayer, btcClient, lcClient := setupTest(t)
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+		lcClient.On("ContainsBlock", ctx, blockHash).Return(true, nil).Once()
+		lcClient.On("GetLatestBlockInfo", ctx).Return(tipAt(104), nil).Once()
+
+		_, err := relayer.TxProof(ctx, &blockHash, txID)
+		assert.ErrorIs(t, err, ErrNotEnoughConfirmations)
+
+		relayer.Config.ProofMinConfirmations = 5
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+		lcClient.On("ContainsBlock", ctx, blockHash).Return(true, nil).Once()
+		lcClient.On("GetLatestBlockInfo", ctx).Return(tipAt(104), nil).Once()
+		lcClient.On("VerifyTx", ctx, int64(100), mock.Anything).Return(true, nil).Once()
+		_, err = relayer.TxProof(ctx, &blockHash, txID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected by light client", func(t *testing.T) {
+		relayer, btcClient, lcClient := setupTest(t)
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+		lcClient.On("ContainsBlock", ctx, blockHash).Return(true, nil).Once()
+		lcClient.On("GetLatestBlockInfo", ctx).Return(tipAt(110), nil).Once()
+		lcClient.On("VerifyTx", ctx, int64(100), mock.Anything).Return(false, nil).Once()
+
+		_, err := relayer.TxProof(ctx, &blockHash, txID)
+		assert.ErrorIs(t, err, ErrProofRejected)
+	})
+
+	t.Run("tx not in block", func(t *testing.T) {
+		relayer, btcClient, _ := setupTest(t)
+		btcClient.On("GetBTCBlockByHash", &blockHash).Return(block, nil).Once()
+
+		_, err := relayer.TxProof(ctx, &blockHash, chainhash.Hash{1})
+		assert.ErrorIs(t, err, types.ErrTxNotInBlock)
+	})
+}
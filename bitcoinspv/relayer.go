@@ -1,15 +1,20 @@
 package bitcoinspv
 
 import (
-	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcindexer"
 	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
 	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
 	"github.com/rs/zerolog"
 )
 
@@ -28,10 +33,22 @@ type Relayer struct {
 
 	// Walrus
 	walrusHandler *WalrusHandler
+	walrusQueue   *walrusQueue
+
+	// Header consensus checks, nil if disabled
+	headerValidator *btctypes.HeaderValidator
+
+	// Coordination with other relayer instances, nil if disabled
+	elector LeaderElector
+
+	// The light client only dev-inspects the header transactions
+	dryRun bool
 
 	// Cache and state
 	btcCache             *types.BTCCache
 	btcConfirmationDepth int64
+	stateStore           *store.DB
+	state                runtimeState
 
 	// Control
 	wg              sync.WaitGroup
@@ -51,6 +68,16 @@ func New(
 	btcIndexer btcindexer.Indexer,
 ) (*Relayer, error) {
 	logger := parentLogger.With().Str("module", "bitcoinspv").Logger()
+
+	var headerValidator *btctypes.HeaderValidator
+	if !cfg.SkipHeaderValidation {
+		params, err := btctypes.GetChainParams(cfg.NetParams)
+		if err != nil {
+			return nil, err
+		}
+		headerValidator = btctypes.NewHeaderValidator(params, btcClient)
+	}
+
 	relayer := &Relayer{
 		Config:               cfg,
 		logger:               logger,
@@ -58,6 +85,7 @@ func New(
 		lcClient:             lcClient,
 		walrusHandler:        walrusHandler,
 		btcIndexer:           btcIndexer,
+		headerValidator:      headerValidator,
 		btcConfirmationDepth: cfg.BTCConfirmationDepth,
 		quitChannel:          make(chan struct{}),
 		isStarted:            false,
@@ -108,6 +136,9 @@ func (r *Relayer) restartAfterShutdown() {
 
 func (r *Relayer) initializeRelayer() {
 	debug := r.logger.Debug()
+	r.startWalrusQueue()
+	r.startWalrusCatchup()
+	r.startWalrusRenewal()
 	debug.Msg("Running bootstrap...")
 	r.multitryBootstrap(false)
 
@@ -146,29 +177,117 @@ func (r *Relayer) WaitForShutdown() {
 	r.wg.Wait()
 }
 
-// UploadToWalrus upload full BTC block to Walrus
-func (r *Relayer) UploadToWalrus(msgBlock *wire.MsgBlock, blockHeight int64, blockHashStr string) {
+// UploadToWalrus stores a full BTC block in Walrus and records its blob.
+func (r *Relayer) UploadToWalrus(msgBlock *wire.MsgBlock, blockHeight int64) error {
 	if msgBlock == nil {
-		r.logger.Warn().Int64("height", blockHeight).Str("hash", blockHashStr).
-			Msg("UploadToWalrus called with nil msgBlock, skipping Walrus store.")
+		return fmt.Errorf("no block to store at height %d", blockHeight)
+	}
+	blob, err := r.walrusHandler.ArchiveBlock(msgBlock, blockHeight)
+	if err != nil {
+		return err
+	}
+	blockHash := msgBlock.BlockHash()
+	r.saveWalrusBlob(context.Background(), blockHeight, blockHash, blob)
+	r.advanceCheckpoint(context.Background(), store.CheckpointWalrus, blockHeight, blockHash)
+	return nil
+}
+
+// walrusUploadWorkers returns the number of concurrent Walrus uploads.
+func (r *Relayer) walrusUploadWorkers() int {
+	if r.Config.WalrusUploadWorkers == 0 {
+		return config.DefaultWalrusUploadWorkers
+	}
+	return r.Config.WalrusUploadWorkers
+}
+
+// startWalrusCatchup stores in Walrus, in the background, the blocks mined since the last
+// block stored in Walrus, typically while the relayer was stopped. Requires the state store,
+// and does nothing until a first block is stored.
+func (r *Relayer) startWalrusCatchup() {
+	if r.walrusHandler == nil || r.stateStore == nil {
+		return
+	}
+	cp, err := r.stateStore.GetCheckpoint(context.Background(), store.CheckpointWalrus)
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to get the Walrus checkpoint, skipping the Walrus catch-up")
+		return
+	}
+	if cp == nil {
+		return
+	}
+	_, tipHeight, err := r.btcClient.GetBTCTipBlock()
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to get the chain tip, skipping the Walrus catch-up")
+		return
+	}
+	if tipHeight <= cp.Height {
 		return
 	}
 
-	var blockBuffer bytes.Buffer
-	if err := msgBlock.Serialize(&blockBuffer); err != nil {
-		r.logger.Error().Err(err).Msgf(
-			"Failed to serialize block %d (%s) for Walrus",
-			blockHeight, blockHashStr,
-		)
-	} else {
-		rawBlockData := blockBuffer.Bytes()
-		_, walrusErr := r.walrusHandler.StoreBlock(rawBlockData, blockHeight, blockHashStr)
-		if walrusErr != nil {
-			r.logger.Warn().Err(walrusErr).Msgf(
-				"Walrus store failed for block %d (%s)",
-				blockHeight, blockHashStr,
-			)
+	backfiller := NewWalrusBackfiller(r.walrusHandler, r.stateStore, r.btcClient, r.walrusUploadWorkers(), r.logger)
+	ctx, cancel := r.createRelayerContext()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer cancel()
+		if _, err := backfiller.Backfill(ctx, cp.Height+1, tipHeight); err != nil && ctx.Err() == nil {
+			r.logger.Warn().Err(err).Msg("Walrus catch-up failed")
+		}
+	}()
+}
 
+// startWalrusQueue starts the background uploads of the blocks to Walrus, resuming the
+// uploads queued by a previous run.
+func (r *Relayer) startWalrusQueue() {
+	if r.walrusHandler == nil {
+		return
+	}
+	if r.walrusQueue == nil {
+		fetch := func(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+			ib, err := r.btcClient.GetBTCBlockByHash(hash)
+			if err != nil {
+				return nil, err
+			}
+			return ib.MsgBlock, nil
 		}
+		r.walrusQueue = newWalrusQueue(r.walrusUploadWorkers(),
+			r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration,
+			r.stateStore, fetch, r.UploadToWalrus, r.logger)
+		if err := r.walrusQueue.load(context.Background()); err != nil {
+			r.logger.Warn().Err(err).Msg("Failed to load the queued Walrus uploads")
+		}
+	}
+	r.walrusQueue.start(r.quitChan(), &r.wg)
+}
+
+// startWalrusRenewal extends the storage of the Walrus blobs expiring soon every
+// walrus-renew-interval.
+func (r *Relayer) startWalrusRenewal() {
+	if r.walrusHandler == nil || r.stateStore == nil || r.Config.WalrusRenewInterval <= 0 {
+		return
 	}
+
+	renewer := NewWalrusRenewer(r.walrusHandler, r.stateStore, r.btcClient, r.Config, r.logger)
+	ctx, cancel := r.createRelayerContext()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer cancel()
+		ticker := time.NewTicker(r.Config.WalrusRenewInterval)
+		defer ticker.Stop()
+		for {
+			_, err := renewer.Renew(ctx)
+			switch {
+			case errors.Is(err, ErrWalrusEpochUnknown):
+				r.logger.Debug().Msg("Walrus epoch not observed yet, skipping the Walrus renewal")
+			case err != nil && ctx.Err() == nil:
+				r.logger.Warn().Err(err).Msg("Walrus renewal failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
 }
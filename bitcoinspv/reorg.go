@@ -0,0 +1,134 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+var (
+	// errCacheOutOfSync is returned when a connected block doesn't extend the cache tip:
+	// the node switched to another branch (reorg) or some block events were missed.
+	errCacheOutOfSync = errors.New("connected block doesn't extend the cache tip")
+	// errReorgTooDeep is returned when the common ancestor of the cache and the new
+	// branch is older than the first block in the cache.
+	errReorgTooDeep = errors.New("common ancestor not found in the cache")
+)
+
+// handleReorg moves the cache to the branch of the connected block without a full bootstrap:
+//  1. walks back from the new block to the last common ancestor with the cache,
+//  2. fetches the blocks of the new branch,
+//  3. rolls back the cache blocks above the common ancestor,
+//  4. adds the new branch to the cache and submits it to the light client.
+//
+// The cache is left untouched when the new branch can't be fetched. It also covers missed
+// block events (the new block is above the cache tip), in which case nothing is rolled back.
+func (r *Relayer) handleReorg(b *btctypes.BlockEvent) error {
+	ancestor, branch, err := r.findForkPoint(b.Height, b.BlockHeader)
+	if err != nil {
+		return err
+	}
+
+	branch = append(branch, b.BlockHeader)
+	blocks := make([]*types.IndexedBlock, 0, len(branch))
+	for i, header := range branch {
+		ib, err := r.indexBlock(header, ancestor+1+int64(i))
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, ib)
+	}
+
+	oldTip := r.btcCache.Last()
+	depth := oldTip.BlockHeight - ancestor
+	r.btcCache.RemoveAfter(ancestor)
+	if depth > 0 {
+		metrics.ReorgsDetected.Inc()
+		metrics.ReorgDepth.Observe(float64(depth))
+		r.logger.Warn().
+			Int64("depth", depth).
+			Int64("common_ancestor", ancestor).
+			Str("old_tip", oldTip.BlockHash().String()).
+			Str("new_tip", b.BlockHeader.BlockHash().String()).
+			Msg("Reorg detected, switching to the new branch")
+	} else {
+		r.logger.Info().Int64("from", ancestor+1).Int64("to", b.Height).
+			Msg("Catching up missed blocks")
+	}
+	for _, ib := range blocks {
+		if err := r.btcCache.Add(ib); err != nil {
+			return fmt.Errorf("can't add block to cache %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.Config.ProcessBlockTimeout)
+	defer cancel()
+	_, err = r.ProcessHeaders(ctx, blocks)
+	return err
+}
+
+// findForkPoint walks back from the header at the given height to the last block it
+// shares with the cache. Missing headers of the new branch are fetched from the node.
+// Returns the height of the common ancestor and the headers of the new branch between
+// the ancestor and the given header (both excluded), ordered by height.
+func (r *Relayer) findForkPoint(height int64, header *wire.BlockHeader) (int64, []*wire.BlockHeader, error) {
+	first := r.btcCache.First()
+	if first == nil {
+		return 0, nil, fmt.Errorf("cache is empty, restart bootstrap process")
+	}
+
+	var branch []*wire.BlockHeader
+	prevHash := header.PrevBlock
+	for h := height - 1; h >= first.BlockHeight; h-- {
+		if cached, err := r.btcCache.FindBlock(h); err == nil && cached.BlockHash() == prevHash {
+			slices.Reverse(branch)
+			return h, branch, nil
+		}
+
+		nodeHeader, err := r.btcClient.GetBTCBlockHeaderByHeight(h)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get header at height %d: %w", h, err)
+		}
+		if nodeHeader.BlockHash() != prevHash {
+			// the node switched branch again while we were walking back
+			return 0, nil, fmt.Errorf("node header at height %d is %s, expected %s",
+				h, nodeHeader.BlockHash(), prevHash)
+		}
+		branch = append(branch, nodeHeader)
+		prevHash = nodeHeader.PrevBlock
+	}
+	return 0, nil, fmt.Errorf("%w: new block at height %d, first cached block at height %d",
+		errReorgTooDeep, height, first.BlockHeight)
+}
+
+// rollbackDisconnected removes a disconnected block and all cache blocks above it.
+// Blocks above the cache tip were never seen and are ignored.
+func (r *Relayer) rollbackDisconnected(height int64, hash chainhash.Hash) error {
+	tip := r.btcCache.Last()
+	if tip == nil {
+		return fmt.Errorf("no blocks found in cache, bootstrap process must be restarted")
+	}
+	if height > tip.BlockHeight {
+		r.logger.Debug().Int64("height", height).Msg("Disconnected block is above the cache tip, skipping")
+		return nil
+	}
+
+	cached, err := r.btcCache.FindBlock(height)
+	if err != nil || cached.BlockHash() != hash {
+		return fmt.Errorf(
+			"cache out of sync during block disconnection, bootstrap process needs to be restarted",
+		)
+	}
+	removed := r.btcCache.RemoveAfter(height - 1)
+	r.logger.Info().Int64("height", height).Int("removed", len(removed)).
+		Msg("Rolled back cache after block disconnection")
+	return nil
+}
@@ -0,0 +1,182 @@
+package bitcoinspv
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/mocks"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+)
+
+const reorgTestFirstHeight = 100
+
+// setupReorgTest returns a relayer with a cache of 5 linked blocks (heights 100...104).
+func setupReorgTest(t *testing.T) (*Relayer, *mocks.MockBTCClient, *mocks.MockBitcoinSPV, []wire.BlockHeader) {
+	t.Helper()
+	genesis := chaincfg.RegressionNetParams.GenesisBlock.Header
+	headers := btctypes.MineTestChain(t, genesis, 5)
+
+	cache, err := types.NewBTCCache(10)
+	assert.NoError(t, err)
+	for i := range headers {
+		assert.NoError(t, cache.Add(&types.IndexedBlock{
+			BlockHeight: reorgTestFirstHeight + int64(i),
+			MsgBlock:    wire.NewMsgBlock(&headers[i]),
+		}))
+	}
+
+	btcClient := mocks.NewMockBTCClient(t)
+	lcClient := mocks.NewMockBitcoinSPV(t)
+	r := &Relayer{
+		btcClient: btcClient,
+		lcClient:  lcClient,
+		btcCache:  cache,
+		logger:    zerolog.Nop(),
+		Config:    testSubmitConfig,
+	}
+	return r, btcClient, lcClient, headers
+}
+
+// forkAt mines count headers on top of prev. The timestamps are shifted by a second so
+// the headers differ from the ones mined by MineTestChain on the same parent.
+func forkAt(t *testing.T, prev wire.BlockHeader, count int) []wire.BlockHeader {
+	t.Helper()
+	first := btctypes.MineTestHeader(t, &prev, prev.Timestamp.Add(10*time.Minute+time.Second),
+		chaincfg.RegressionNetParams.PowLimitBits)
+	return append([]wire.BlockHeader{first}, btctypes.MineTestChain(t, first, count-1)...)
+}
+
+func cacheHashes(cache *types.BTCCache) []string {
+	var hashes []string
+	for _, b := range cache.GetAllBlocks() {
+		hashes = append(hashes, b.BlockHash().String())
+	}
+	return hashes
+}
+
+func TestHandleReorg(t *testing.T) {
+	t.Run("reorg of depth 2", func(t *testing.T) {
+		r, btcClient, lcClient, headers := setupReorgTest(t)
+		// new branch forks after height 102: 103', 104', 105'
+		fork := forkAt(t, headers[2], 3)
+
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(104)).Return(&fork[1], nil).Once()
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(103)).Return(&fork[0], nil).Once()
+		lcClient.On("ContainsBlocks", mock.Anything, mock.Anything).Return([]bool{false, false, false}, nil).Once()
+		lcClient.On("GetLatestBlockInfo", mock.Anything).Return(&clients.BlockInfo{Hash: &fork[0].PrevBlock}, nil).Once()
+		lcClient.On("InsertHeaders", mock.Anything, mock.Anything).Return("digest", nil).Twice()
+
+		err := r.onConnectedBlock(btctypes.NewBlockEvent(btctypes.BlockConnected, 105, &fork[2]))
+		assert.NoError(t, err)
+
+		expected := []string{
+			headers[0].BlockHash().String(), headers[1].BlockHash().String(), headers[2].BlockHash().String(),
+			fork[0].BlockHash().String(), fork[1].BlockHash().String(), fork[2].BlockHash().String(),
+		}
+		assert.Equal(t, expected, cacheHashes(r.btcCache))
+		assert.Equal(t, int64(105), r.btcCache.Last().BlockHeight)
+	})
+
+	t.Run("missed block events", func(t *testing.T) {
+		r, btcClient, lcClient, headers := setupReorgTest(t)
+		next := btctypes.MineTestChain(t, headers[4], 3) // 105, 106, 107
+
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(106)).Return(&next[1], nil).Once()
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(105)).Return(&next[0], nil).Once()
+		lcClient.On("ContainsBlocks", mock.Anything, mock.Anything).Return([]bool{false, false, false}, nil).Once()
+		lcClient.On("GetLatestBlockInfo", mock.Anything).Return(&clients.BlockInfo{Hash: &next[0].PrevBlock}, nil).Once()
+		lcClient.On("InsertHeaders", mock.Anything, mock.Anything).Return("digest", nil).Twice()
+
+		err := r.onConnectedBlock(btctypes.NewBlockEvent(btctypes.BlockConnected, 107, &next[2]))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8), r.btcCache.Size())
+		assert.Equal(t, int64(107), r.btcCache.Last().BlockHeight)
+		assert.Equal(t, next[2].BlockHash(), r.btcCache.Last().BlockHash())
+	})
+
+	t.Run("common ancestor older than the cache", func(t *testing.T) {
+		r, btcClient, _, headers := setupReorgTest(t)
+		// branch forking before the first cached block
+		genesis := chaincfg.RegressionNetParams.GenesisBlock.Header
+		fork := forkAt(t, genesis, 6) // heights 100...105
+		for i := 4; i >= 0; i-- {
+			btcClient.On("GetBTCBlockHeaderByHeight", int64(reorgTestFirstHeight+i)).
+				Return(&fork[i], nil).Once()
+		}
+
+		err := r.onConnectedBlock(btctypes.NewBlockEvent(btctypes.BlockConnected, 105, &fork[5]))
+		assert.ErrorIs(t, err, errReorgTooDeep)
+		// cache is left untouched
+		assert.Equal(t, int64(5), r.btcCache.Size())
+		assert.Equal(t, headers[4].BlockHash(), r.btcCache.Last().BlockHash())
+	})
+
+	t.Run("new branch can't be fetched", func(t *testing.T) {
+		r, btcClient, _, headers := setupReorgTest(t)
+		// full blocks are fetched for Walrus
+		r.walrusHandler = &WalrusHandler{}
+		fork := forkAt(t, headers[2], 3) // 103', 104', 105'
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(104)).Return(&fork[1], nil).Once()
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(103)).Return(&fork[0], nil).Once()
+		btcClient.On("GetBTCBlockByHash", mock.Anything).Return(nil, errors.New("node unavailable")).Once()
+
+		err := r.onConnectedBlock(btctypes.NewBlockEvent(btctypes.BlockConnected, 105, &fork[2]))
+		assert.Error(t, err)
+		// cache is left untouched
+		assert.Equal(t, int64(5), r.btcCache.Size())
+		assert.Equal(t, headers[4].BlockHash(), r.btcCache.Last().BlockHash())
+	})
+
+	t.Run("node switched branch during the walk back", func(t *testing.T) {
+		r, btcClient, _, headers := setupReorgTest(t)
+		fork := forkAt(t, headers[2], 3)  // 103', 104', 105'
+		other := forkAt(t, headers[1], 3) // 102'', 103'', 104''
+		btcClient.On("GetBTCBlockHeaderByHeight", int64(104)).Return(&other[2], nil).Once()
+
+		err := r.onConnectedBlock(btctypes.NewBlockEvent(btctypes.BlockConnected, 105, &fork[2]))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errReorgTooDeep)
+		assert.Equal(t, headers[4].BlockHash(), r.btcCache.Last().BlockHash())
+	})
+}
+
+func TestOnDisconnectedBlock(t *testing.T) {
+	t.Run("disconnect tip", func(t *testing.T) {
+		r, _, _, headers := setupReorgTest(t)
+		err := r.onDisconnectedBlock(btctypes.NewBlockEvent(btctypes.BlockDisconnected, 104, &headers[4]))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(103), r.btcCache.Last().BlockHeight)
+	})
+
+	t.Run("disconnect below tip", func(t *testing.T) {
+		r, _, _, headers := setupReorgTest(t)
+		err := r.onDisconnectedBlock(btctypes.NewBlockEvent(btctypes.BlockDisconnected, 102, &headers[2]))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(101), r.btcCache.Last().BlockHeight)
+	})
+
+	t.Run("disconnect above tip", func(t *testing.T) {
+		r, _, _, headers := setupReorgTest(t)
+		next := btctypes.MineTestChain(t, headers[4], 1)
+		err := r.onDisconnectedBlock(btctypes.NewBlockEvent(btctypes.BlockDisconnected, 105, &next[0]))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), r.btcCache.Size())
+	})
+
+	t.Run("unknown block", func(t *testing.T) {
+		r, _, _, headers := setupReorgTest(t)
+		fork := forkAt(t, headers[2], 1)
+		err := r.onDisconnectedBlock(btctypes.NewBlockEvent(btctypes.BlockDisconnected, 103, &fork[0]))
+		assert.Error(t, err)
+		assert.Equal(t, int64(5), r.btcCache.Size())
+	})
+}
@@ -8,7 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
 	sui_errors "github.com/gonative-cc/relayer/bitcoinspv/clients/sui"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
 	"github.com/rs/zerolog"
 )
 
@@ -24,7 +26,22 @@ const (
 	categoryUnknown
 )
 
+func (c ErrorCategory) String() string {
+	switch c {
+	case categoryRetryable:
+		return "retryable"
+	case categoryNonRecoverable:
+		return "non_recoverable"
+	default:
+		return "unknown"
+	}
+}
+
 func classifyError(logger zerolog.Logger, err error) ErrorCategory {
+	// an unknown block doesn't show up by asking again
+	if errors.Is(err, clients.ErrBlockNotFound) {
+		return categoryNonRecoverable
+	}
 	if !errors.Is(err, sui_errors.ErrSuiTransactionFailed) {
 		return categoryRetryable
 	}
@@ -58,6 +75,7 @@ func RetryDo(
 	}
 
 	category := classifyError(logger, err)
+	metrics.RetryFailures.WithLabelValues(category.String()).Inc()
 
 	switch category {
 	case categoryNonRecoverable:
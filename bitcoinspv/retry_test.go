@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
 	sui_errors "github.com/gonative-cc/relayer/bitcoinspv/clients/sui"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +39,10 @@ func TestNonRecoverableError(t *testing.T) {
 
 		testRetryDo(t, simulatedOtherFailureErr)
 	})
+
+	t.Run("BlockNotFound", func(t *testing.T) {
+		testRetryDo(t, fmt.Errorf("%w: 0000", clients.ErrBlockNotFound))
+	})
 }
 
 func TestRetryableError(t *testing.T) {
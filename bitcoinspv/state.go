@@ -0,0 +1,164 @@
+package bitcoinspv
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+)
+
+// SetStateStore sets the local store used to persist submitted chunks and sync checkpoints.
+// When the store is nil (default), the relayer keeps no state between restarts.
+func (r *Relayer) SetStateStore(db *store.DB) {
+	r.stateStore = db
+}
+
+// recordChunk persists the outcome of a chunk submission. On success the light client
+// checkpoint is moved to the last header of the chunk.
+// Store errors are only logged: the local state must never block header relaying.
+func (r *Relayer) recordChunk(ctx context.Context, chunk Chunk, txDigest string, submitErr error) {
+	if r.stateStore == nil || len(chunk.Headers) == 0 {
+		return
+	}
+
+	firstHash := chunk.Headers[0].BlockHash()
+	lastHash := chunk.Headers[len(chunk.Headers)-1].BlockHash()
+	params := store.InsertSubmittedChunkParams{
+		FromHeight: chunk.From,
+		ToHeight:   chunk.To,
+		FirstHash:  firstHash[:],
+		LastHash:   lastHash[:],
+		TxDigest:   txDigest,
+		Status:     store.ChunkSubmitted,
+		Timestamp:  time.Now().Unix(),
+	}
+	if submitErr != nil {
+		params.Status = store.ChunkFailed
+		params.Note = sql.NullString{String: submitErr.Error(), Valid: true}
+	}
+	if err := r.stateStore.InsertSubmittedChunk(ctx, params); err != nil {
+		r.logger.Warn().Err(err).Int64("from", chunk.From).Int64("to", chunk.To).
+			Msg("Failed to record submitted chunk")
+	}
+
+	if submitErr == nil {
+		r.saveCheckpoint(ctx, store.CheckpointLightClient, chunk.To, lastHash)
+	}
+}
+
+// saveCheckpoint moves the named sync checkpoint to the given block.
+func (r *Relayer) saveCheckpoint(ctx context.Context, name store.CheckpointName, height int64, hash chainhash.Hash) {
+	if r.stateStore == nil {
+		return
+	}
+
+	cp := store.Checkpoint{
+		Name:      name,
+		Height:    height,
+		BlockHash: hash[:],
+		Timestamp: time.Now().Unix(),
+	}
+	if err := r.stateStore.UpsertCheckpoint(ctx, cp); err != nil {
+		r.logger.Warn().Err(err).Str("checkpoint", string(name)).Int64("height", height).
+			Msg("Failed to save checkpoint")
+	}
+}
+
+// advanceCheckpoint moves the named sync checkpoint to the given block, unless it's already at
+// a higher block. Used for the blocks handled out of order.
+func (r *Relayer) advanceCheckpoint(ctx context.Context, name store.CheckpointName, height int64, hash chainhash.Hash) {
+	if r.stateStore == nil {
+		return
+	}
+
+	cp := store.Checkpoint{
+		Name:      name,
+		Height:    height,
+		BlockHash: hash[:],
+		Timestamp: time.Now().Unix(),
+	}
+	if err := r.stateStore.AdvanceCheckpoint(ctx, cp); err != nil {
+		r.logger.Warn().Err(err).Str("checkpoint", string(name)).Int64("height", height).
+			Msg("Failed to save checkpoint")
+	}
+}
+
+// saveWalrusBlob records the Walrus blob storing a block, so the block can be read back from
+// the Walrus aggregators.
+func (r *Relayer) saveWalrusBlob(ctx context.Context, height int64, hash chainhash.Hash, blob *StoredBlob) {
+	if r.stateStore == nil {
+		return
+	}
+
+	if err := recordWalrusBlob(ctx, r.stateStore, height, hash, blob); err != nil {
+		r.logger.Warn().Err(err).Int64("height", height).Str("blob_id", blob.BlobID).
+			Msg("Failed to save Walrus blob")
+	}
+}
+
+// recordWalrusBlob records the Walrus blob storing a block and, when the storage was bought,
+// the Walrus epoch observed in the store response.
+func recordWalrusBlob(ctx context.Context, db *store.DB, height int64, hash chainhash.Hash, blob *StoredBlob) error {
+	if err := db.UpsertWalrusBlob(ctx, walrusBlobRecord(height, hash, blob)); err != nil {
+		return err
+	}
+	if blob.StartEpoch == 0 {
+		return nil
+	}
+	return db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: blob.StartEpoch, Timestamp: time.Now().Unix()})
+}
+
+// walrusBlobRecord returns the state store record of a block stored in Walrus.
+func walrusBlobRecord(height int64, hash chainhash.Hash, blob *StoredBlob) store.WalrusBlob {
+	return store.WalrusBlob{
+		BlockHash: hash[:],
+		Height:    height,
+		BlobID:    blob.BlobID,
+		Cost:      blob.Cost,
+		EndEpoch:  blob.EndEpoch,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// resumeFromCheckpoint drops the blocks that are already covered by the stored light client
+// checkpoint. The checkpoint is used only when the block at its height is in the given list,
+// has the same hash and is still known to the light client. Otherwise (no store, no checkpoint,
+// reorg since the last run) all blocks are returned.
+func (r *Relayer) resumeFromCheckpoint(
+	ctx context.Context,
+	blocks []*types.IndexedBlock,
+) []*types.IndexedBlock {
+	if r.stateStore == nil || len(blocks) == 0 {
+		return blocks
+	}
+
+	cp, err := r.stateStore.GetCheckpoint(ctx, store.CheckpointLightClient)
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to read light client checkpoint")
+		return blocks
+	}
+	if cp == nil {
+		return blocks
+	}
+
+	idx := cp.Height - blocks[0].BlockHeight
+	if idx < 0 || idx >= int64(len(blocks)) {
+		return blocks
+	}
+	cpHash, err := chainhash.NewHash(cp.BlockHash)
+	if err != nil || blocks[idx].BlockHash() != *cpHash {
+		return blocks
+	}
+
+	known, err := r.lcClient.ContainsBlock(ctx, *cpHash)
+	if err != nil || !known {
+		return blocks
+	}
+
+	r.logger.Info().Int64("height", cp.Height).Str("hash", cpHash.String()).
+		Msg("Resuming from light client checkpoint")
+	return blocks[idx+1:]
+}
@@ -0,0 +1,145 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/mocks"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordChunk(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 4, 100)
+	chunks := breakIntoChunks(testBlocks, 2)
+
+	r := &Relayer{logger: zerolog.Nop(), Config: testSubmitConfig}
+	r.SetStateStore(storetest.InitTestDB(ctx, t))
+
+	r.recordChunk(ctx, chunks[0], "digest1", nil)
+	r.recordChunk(ctx, chunks[1], "", errors.New("MoveAbort"))
+
+	listed, err := r.stateStore.ListSubmittedChunks(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 2)
+	assert.Equal(t, store.ChunkFailed, listed[0].Status)
+	assert.Equal(t, "MoveAbort", listed[0].Note.String)
+	assert.Equal(t, store.ChunkSubmitted, listed[1].Status)
+	assert.Equal(t, "digest1", listed[1].TxDigest)
+
+	// only the successful chunk moves the light client checkpoint
+	cp, err := r.stateStore.GetCheckpoint(ctx, store.CheckpointLightClient)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), cp.Height)
+	hash := testBlocks[1].BlockHash()
+	assert.Equal(t, hash[:], cp.BlockHash)
+}
+
+func TestSubmitHeaderMessagesDryRun(t *testing.T) {
+	ctx := context.Background()
+	chunks := breakIntoChunks(types.CreateTestIndexedBlocks(t, 2, 100), 2)
+
+	mockLC := mocks.NewMockBitcoinSPV(t)
+	mockLC.On("InsertHeaders", ctx, chunks[0].Headers).Return("inspected", nil).Once()
+	r := &Relayer{lcClient: mockLC, logger: zerolog.Nop(), Config: testSubmitConfig}
+	r.SetStateStore(storetest.InitTestDB(ctx, t))
+	r.SetDryRun(true)
+
+	_, err := r.submitHeaderMessages(ctx, chunks[0])
+	assert.NoError(t, err)
+
+	// the dev-inspected chunk is neither recorded nor moves the light client checkpoint
+	listed, err := r.stateStore.ListSubmittedChunks(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, listed)
+	cp, err := r.stateStore.GetCheckpoint(ctx, store.CheckpointLightClient)
+	assert.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 5, 100) // heights 100, 101, 102, 103, 104
+	otherBlocks := types.CreateTestIndexedBlocks(t, 5, 200)
+
+	tests := []struct {
+		name       string
+		checkpoint *types.IndexedBlock
+		mockSetup  func(mockLC *mocks.MockBitcoinSPV)
+		expected   []*types.IndexedBlock
+	}{
+		{
+			name:     "no checkpoint",
+			expected: testBlocks,
+		},
+		{
+			name:       "checkpoint known to light client",
+			checkpoint: testBlocks[2],
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(true, nil).Once()
+			},
+			expected: testBlocks[3:],
+		},
+		{
+			name:       "checkpoint not known to light client",
+			checkpoint: testBlocks[2],
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(false, nil).Once()
+			},
+			expected: testBlocks,
+		},
+		{
+			name:       "checkpoint hash differs from cache",
+			checkpoint: &types.IndexedBlock{BlockHeight: 102, MsgBlock: otherBlocks[0].MsgBlock},
+			expected:   testBlocks,
+		},
+		{
+			name:       "checkpoint outside of cache",
+			checkpoint: otherBlocks[0],
+			expected:   testBlocks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLC := mocks.NewMockBitcoinSPV(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockLC)
+			}
+			r := &Relayer{lcClient: mockLC, logger: zerolog.Nop(), Config: testSubmitConfig}
+			r.SetStateStore(storetest.InitTestDB(ctx, t))
+			if tt.checkpoint != nil {
+				r.saveCheckpoint(ctx, store.CheckpointLightClient,
+					tt.checkpoint.BlockHeight, tt.checkpoint.BlockHash())
+			}
+
+			assert.Equal(t, tt.expected, r.resumeFromCheckpoint(ctx, testBlocks))
+			mockLC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSaveWalrusBlob(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 1, 100)
+	hash := testBlocks[0].BlockHash()
+
+	// no-op without a store
+	r := &Relayer{logger: zerolog.Nop(), Config: testSubmitConfig}
+	r.saveWalrusBlob(ctx, 100, hash, &StoredBlob{BlobID: "blob"})
+
+	r.SetStateStore(storetest.InitTestDB(ctx, t))
+	r.saveWalrusBlob(ctx, 100, hash, &StoredBlob{BlobID: "blob", Cost: 10, EndEpoch: 5})
+
+	blob, err := r.stateStore.GetWalrusBlob(ctx, hash[:])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), blob.Height)
+	assert.Equal(t, "blob", blob.BlobID)
+	assert.Equal(t, int64(10), blob.Cost)
+	assert.Equal(t, int64(5), blob.EndEpoch)
+}
@@ -0,0 +1,166 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+)
+
+// Status is a snapshot of the relayer progress.
+type Status struct {
+	BTCTipHeight     int64  `json:"btc_tip_height"`
+	BTCTipHash       string `json:"btc_tip_hash,omitempty"`
+	LCTipHeight      int64  `json:"lc_tip_height"`
+	LCTipHash        string `json:"lc_tip_hash,omitempty"`
+	CacheFirstHeight int64  `json:"cache_first_height"`
+	CacheLastHeight  int64  `json:"cache_last_height"`
+	Bootstrapped     bool   `json:"bootstrapped"`
+	Subscribed       bool   `json:"subscribed"`
+	// LCOnHeavierChain is set when the light client best chain has at least as much work
+	// as the branch of the Bitcoin node, so the node headers are not submitted.
+	LCOnHeavierChain bool `json:"lc_on_heavier_chain"`
+	// Role is "leader" or "standby" when the coordination between relayer instances is enabled.
+	Role string `json:"role,omitempty"`
+	// LastBlockEvent is the time when the last block event was received.
+	LastBlockEvent *time.Time `json:"last_block_event,omitempty"`
+	// SinceLastBlockEvent is the number of seconds since LastBlockEvent.
+	SinceLastBlockEvent float64    `json:"since_last_block_event_seconds,omitempty"`
+	LastSubmission      *time.Time `json:"last_submission,omitempty"`
+	LastSubmissionError string     `json:"last_submission_error,omitempty"`
+	LastSubmissionErrAt *time.Time `json:"last_submission_error_at,omitempty"`
+	// Errors lists the failures that happened while collecting the status.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// runtimeState tracks the relayer runtime information reported by Status and Ready.
+type runtimeState struct {
+	mu             sync.RWMutex
+	btcCache       *types.BTCCache
+	bootstrapped   bool
+	subscribed     bool
+	lcHeavier      bool
+	lastBlockEvent time.Time
+	lastSubmission time.Time
+	lastSubmitErr  error
+	lastSubmitErrT time.Time
+}
+
+func (s *runtimeState) setCache(cache *types.BTCCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.btcCache = cache
+}
+
+func (s *runtimeState) setBootstrapped(v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bootstrapped = v
+}
+
+func (s *runtimeState) setSubscribed(v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribed = v
+}
+
+func (s *runtimeState) setLCOnHeavierChain(v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lcHeavier = v
+}
+
+func (s *runtimeState) blockEventReceived() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastBlockEvent = time.Now()
+}
+
+func (s *runtimeState) submitted(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastSubmitErr = err
+		s.lastSubmitErrT = time.Now()
+		return
+	}
+	s.lastSubmission = time.Now()
+}
+
+// Ready returns nil when the relayer is bootstrapped, subscribed to new blocks and the
+// light client is reachable. Otherwise it returns the reason why it is not ready.
+func (r *Relayer) Ready(ctx context.Context) error {
+	r.state.mu.RLock()
+	bootstrapped, subscribed := r.state.bootstrapped, r.state.subscribed
+	r.state.mu.RUnlock()
+
+	if !bootstrapped {
+		return errors.New("bootstrap not finished")
+	}
+	if !subscribed {
+		return errors.New("not subscribed to new blocks")
+	}
+	if _, err := r.lcClient.GetLatestBlockInfo(ctx); err != nil {
+		return fmt.Errorf("light client not reachable: %w", err)
+	}
+	return nil
+}
+
+// Status queries the Bitcoin node and the light client tips and returns them
+// together with the relayer runtime information.
+func (r *Relayer) Status(ctx context.Context) Status {
+	var st Status
+	if hash, height, err := r.btcClient.GetBTCTipBlock(); err != nil {
+		st.Errors = append(st.Errors, fmt.Sprintf("btc node: %v", err))
+	} else {
+		st.BTCTipHeight = height
+		st.BTCTipHash = hash.String()
+	}
+	if lcBlock, err := r.lcClient.GetLatestBlockInfo(ctx); err != nil {
+		st.Errors = append(st.Errors, fmt.Sprintf("light client: %v", err))
+	} else {
+		st.LCTipHeight = lcBlock.Height
+		if lcBlock.Hash != nil {
+			st.LCTipHash = lcBlock.Hash.String()
+		}
+	}
+
+	if r.elector != nil {
+		st.Role = "standby"
+		if r.elector.IsLeader() {
+			st.Role = "leader"
+		}
+	}
+
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	if r.state.btcCache != nil {
+		if first := r.state.btcCache.First(); first != nil {
+			st.CacheFirstHeight = first.BlockHeight
+		}
+		if last := r.state.btcCache.Last(); last != nil {
+			st.CacheLastHeight = last.BlockHeight
+		}
+	}
+	st.Bootstrapped = r.state.bootstrapped
+	st.Subscribed = r.state.subscribed
+	st.LCOnHeavierChain = r.state.lcHeavier
+	if !r.state.lastBlockEvent.IsZero() {
+		t := r.state.lastBlockEvent
+		st.LastBlockEvent = &t
+		st.SinceLastBlockEvent = time.Since(t).Seconds()
+	}
+	if !r.state.lastSubmission.IsZero() {
+		t := r.state.lastSubmission
+		st.LastSubmission = &t
+	}
+	if r.state.lastSubmitErr != nil {
+		t := r.state.lastSubmitErrT
+		st.LastSubmissionError = r.state.lastSubmitErr.Error()
+		st.LastSubmissionErrAt = &t
+	}
+	return st
+}
@@ -0,0 +1,60 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	ctx := context.Background()
+	r, _, lcClient := setupTest(t)
+
+	assert.ErrorContains(t, r.Ready(ctx), "bootstrap")
+
+	r.state.setBootstrapped(true)
+	assert.ErrorContains(t, r.Ready(ctx), "subscribed")
+
+	r.state.setSubscribed(true)
+	lcClient.On("GetLatestBlockInfo", ctx).Return(nil, errors.New("connection refused")).Once()
+	assert.ErrorContains(t, r.Ready(ctx), "light client not reachable")
+
+	lcClient.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{Height: 100}, nil).Once()
+	assert.NoError(t, r.Ready(ctx))
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	r, btcClient, lcClient := setupTest(t)
+
+	cache, err := types.NewBTCCache(10)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Init(types.CreateTestIndexedBlocks(t, 3, 98)))
+	r.state.setCache(cache)
+	r.state.blockEventReceived()
+	r.state.submitted(errors.New("MoveAbort"))
+
+	btcClient.On("GetBTCTipBlock").Return(&chainhash.Hash{}, int64(105), nil).Once()
+	lcClient.On("GetLatestBlockInfo", ctx).Return(nil, errors.New("timeout")).Once()
+
+	st := r.Status(ctx)
+	assert.Equal(t, int64(105), st.BTCTipHeight)
+	assert.Equal(t, int64(0), st.LCTipHeight)
+	assert.Len(t, st.Errors, 1)
+	assert.Equal(t, int64(98), st.CacheFirstHeight)
+	assert.Equal(t, int64(100), st.CacheLastHeight)
+	assert.NotNil(t, st.LastBlockEvent)
+	assert.Equal(t, "MoveAbort", st.LastSubmissionError)
+	assert.Nil(t, st.LastSubmission)
+	assert.Empty(t, st.Role)
+
+	r.SetLeaderElector(&testElector{leader: true})
+	btcClient.On("GetBTCTipBlock").Return(&chainhash.Hash{}, int64(105), nil).Once()
+	lcClient.On("GetLatestBlockInfo", ctx).Return(&clients.BlockInfo{Height: 100}, nil).Once()
+	assert.Equal(t, "leader", r.Status(ctx).Role)
+}
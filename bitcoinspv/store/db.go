@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // Import the SQLite driver
+)
+
+// ChunkStatus represents the outcome of a header chunk submission to the light client.
+type ChunkStatus byte
+
+// Chunk status constants
+const (
+	ChunkSubmitted ChunkStatus = iota
+	ChunkFailed
+)
+
+// CheckpointName identifies a sync watermark tracked by the relayer.
+type CheckpointName string
+
+// Checkpoint names
+const (
+	// CheckpointLightClient is the last known light client tip.
+	CheckpointLightClient CheckpointName = "light_client"
+	// CheckpointIndexer is the highest block sent to the nBTC indexer.
+	CheckpointIndexer CheckpointName = "indexer"
+	// CheckpointWalrus is the highest block stored in Walrus.
+	CheckpointWalrus CheckpointName = "walrus"
+)
+
+// DB holds the database connection and provides methods for interacting with
+// the bitcoin-spv relayer state.
+type DB struct {
+	conn  *sql.DB
+	mutex *sync.RWMutex
+	Querier
+}
+
+// NewDB creates a new DB instance
+func NewDB(dbPath string) (*DB, error) {
+	conn, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("store: can't open sqlite3: %w", err)
+	}
+	queries := New(conn)
+	return &DB{conn: conn, mutex: &sync.RWMutex{}, Querier: queries}, nil
+}
+
+//go:embed schema.sql
+var content embed.FS
+
+// InitDB initializes the database and creates the tables.
+func (db *DB) InitDB(ctx context.Context) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	schema, err := content.ReadFile("schema.sql")
+	if err != nil {
+		return fmt.Errorf("store: reading schema file: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, string(schema))
+	if err != nil {
+		return fmt.Errorf("store: creating tables: %w", err)
+	}
+	return nil
+}
+
+// InsertSubmittedChunk records a header chunk submission attempt.
+func (db *DB) InsertSubmittedChunk(ctx context.Context, chunk InsertSubmittedChunkParams) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	err := db.Querier.InsertSubmittedChunk(ctx, &chunk)
+	if err != nil {
+		return fmt.Errorf("store: inserting submitted_chunk: %w", err)
+	}
+	return nil
+}
+
+// ListSubmittedChunks returns the most recent chunk submissions, newest first.
+func (db *DB) ListSubmittedChunks(ctx context.Context, limit int64) ([]*SubmittedChunk, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	chunks, err := db.Querier.ListSubmittedChunks(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("store: listing submitted_chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// GetLastSubmittedChunk returns the most recent chunk with the given status.
+// Returns nil, nil when there is no such chunk.
+func (db *DB) GetLastSubmittedChunk(ctx context.Context, status ChunkStatus) (*SubmittedChunk, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	chunk, err := db.Querier.GetLastSubmittedChunk(ctx, status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: getting last submitted_chunk: %w", err)
+	}
+	return chunk, nil
+}
+
+// UpsertCheckpoint creates or moves the named checkpoint.
+func (db *DB) UpsertCheckpoint(ctx context.Context, cp Checkpoint) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := UpsertCheckpointParams(cp)
+	err := db.Querier.UpsertCheckpoint(ctx, &params)
+	if err != nil {
+		return fmt.Errorf("store: upserting checkpoint %s: %w", cp.Name, err)
+	}
+	return nil
+}
+
+// AdvanceCheckpoint creates or moves the named checkpoint, unless it's already at a higher
+// block.
+func (db *DB) AdvanceCheckpoint(ctx context.Context, cp Checkpoint) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := AdvanceCheckpointParams(cp)
+	err := db.Querier.AdvanceCheckpoint(ctx, &params)
+	if err != nil {
+		return fmt.Errorf("store: advancing checkpoint %s: %w", cp.Name, err)
+	}
+	return nil
+}
+
+// GetCheckpoint retrieves the named checkpoint. Returns nil, nil when it was never set.
+func (db *DB) GetCheckpoint(ctx context.Context, name CheckpointName) (*Checkpoint, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	cp, err := db.Querier.GetCheckpoint(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: getting checkpoint %s: %w", name, err)
+	}
+	return cp, nil
+}
+
+// UpsertWalrusBlob records the Walrus blob storing a block.
+func (db *DB) UpsertWalrusBlob(ctx context.Context, blob WalrusBlob) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := UpsertWalrusBlobParams(blob)
+	err := db.Querier.UpsertWalrusBlob(ctx, &params)
+	if err != nil {
+		return fmt.Errorf("store: upserting walrus_blob %d: %w", blob.Height, err)
+	}
+	return nil
+}
+
+// GetWalrusBlob returns the Walrus blob of the given block. Returns nil, nil when the block
+// is not stored.
+func (db *DB) GetWalrusBlob(ctx context.Context, blockHash []byte) (*WalrusBlob, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	blob, err := db.Querier.GetWalrusBlob(ctx, blockHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: getting walrus_blob: %w", err)
+	}
+	return blob, nil
+}
+
+// DeleteWalrusBlob removes the Walrus blob of the given block.
+func (db *DB) DeleteWalrusBlob(ctx context.Context, blockHash []byte) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if err := db.Querier.DeleteWalrusBlob(ctx, blockHash); err != nil {
+		return fmt.Errorf("store: deleting walrus_blob: %w", err)
+	}
+	return nil
+}
+
+// ListWalrusBlobs returns the Walrus blobs of the blocks in the height range, both included,
+// ordered by height. A height has several blobs when the stored blocks were reorganized.
+func (db *DB) ListWalrusBlobs(ctx context.Context, fromHeight, toHeight int64) ([]*WalrusBlob, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	blobs, err := db.Querier.ListWalrusBlobs(ctx, &ListWalrusBlobsParams{FromHeight: fromHeight, ToHeight: toHeight})
+	if err != nil {
+		return nil, fmt.Errorf("store: listing walrus_blobs: %w", err)
+	}
+	return blobs, nil
+}
+
+// GetMaxWalrusBlobCost returns the highest storage cost of the recorded Walrus blobs, zero
+// when no blob was bought.
+func (db *DB) GetMaxWalrusBlobCost(ctx context.Context) (int64, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	cost, err := db.Querier.GetMaxWalrusBlobCost(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("store: getting max walrus_blob cost: %w", err)
+	}
+	return cost, nil
+}
+
+// ListExpiringWalrusBlobs returns the Walrus blobs of the blocks from the given height
+// expiring at or before maxEndEpoch, soonest expiring first.
+func (db *DB) ListExpiringWalrusBlobs(ctx context.Context, maxEndEpoch, fromHeight int64) ([]*WalrusBlob, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	params := ListExpiringWalrusBlobsParams{MaxEndEpoch: maxEndEpoch, FromHeight: fromHeight}
+	blobs, err := db.Querier.ListExpiringWalrusBlobs(ctx, &params)
+	if err != nil {
+		return nil, fmt.Errorf("store: listing expiring walrus_blobs: %w", err)
+	}
+	return blobs, nil
+}
+
+// UpsertWalrusEpoch records an observed Walrus epoch, adding the renew cost to the cost
+// already spent in the epoch. The timestamp of an epoch is the one of its first record.
+func (db *DB) UpsertWalrusEpoch(ctx context.Context, epoch WalrusEpoch) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := UpsertWalrusEpochParams(epoch)
+	if err := db.Querier.UpsertWalrusEpoch(ctx, &params); err != nil {
+		return fmt.Errorf("store: upserting walrus_epoch %d: %w", epoch.Epoch, err)
+	}
+	return nil
+}
+
+// GetWalrusEpoch returns the given Walrus epoch. Returns nil, nil when it was never recorded.
+func (db *DB) GetWalrusEpoch(ctx context.Context, epoch int64) (*WalrusEpoch, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	e, err := db.Querier.GetWalrusEpoch(ctx, epoch)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: getting walrus_epoch %d: %w", epoch, err)
+	}
+	return e, nil
+}
+
+// GetLatestWalrusEpoch returns the highest recorded Walrus epoch. Returns nil, nil when no
+// epoch was recorded.
+func (db *DB) GetLatestWalrusEpoch(ctx context.Context) (*WalrusEpoch, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	e, err := db.Querier.GetLatestWalrusEpoch(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: getting latest walrus_epoch: %w", err)
+	}
+	return e, nil
+}
+
+// InsertWalrusUpload queues the upload of a block to Walrus. Queuing a block twice is a no-op.
+func (db *DB) InsertWalrusUpload(ctx context.Context, blockHash []byte, height int64) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := InsertWalrusUploadParams{BlockHash: blockHash, Height: height, Timestamp: time.Now().Unix()}
+	if err := db.Querier.InsertWalrusUpload(ctx, &params); err != nil {
+		return fmt.Errorf("store: inserting walrus_upload %d: %w", height, err)
+	}
+	return nil
+}
+
+// UpdateWalrusUploadAttempt records a failed upload attempt of a queued block.
+func (db *DB) UpdateWalrusUploadAttempt(ctx context.Context, blockHash []byte, attempts int64, lastErr string) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	params := UpdateWalrusUploadAttemptParams{
+		Attempts:  attempts,
+		LastError: sql.NullString{String: lastErr, Valid: lastErr != ""},
+		BlockHash: blockHash,
+	}
+	if err := db.Querier.UpdateWalrusUploadAttempt(ctx, &params); err != nil {
+		return fmt.Errorf("store: updating walrus_upload: %w", err)
+	}
+	return nil
+}
+
+// DeleteWalrusUpload removes a block from the Walrus upload queue.
+func (db *DB) DeleteWalrusUpload(ctx context.Context, blockHash []byte) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if err := db.Querier.DeleteWalrusUpload(ctx, blockHash); err != nil {
+		return fmt.Errorf("store: deleting walrus_upload: %w", err)
+	}
+	return nil
+}
+
+// ListWalrusUploads returns the queued Walrus uploads, ordered by height.
+func (db *DB) ListWalrusUploads(ctx context.Context) ([]*WalrusUpload, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	uploads, err := db.Querier.ListWalrusUploads(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("store: listing walrus_uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+// Close closes the db connection
+func (db *DB) Close() error {
+	// make sure other read / writes are done
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.conn.Close()
+}
@@ -0,0 +1,207 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"gotest.tools/v3/assert"
+)
+
+func Test_InsertSubmittedChunk(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	chunks := []store.InsertSubmittedChunkParams{
+		{FromHeight: 100, ToHeight: 109, FirstHash: []byte("a"), LastHash: []byte("b"),
+			TxDigest: "digest1", Status: store.ChunkSubmitted, Timestamp: time.Now().Unix()},
+		{FromHeight: 110, ToHeight: 119, FirstHash: []byte("c"), LastHash: []byte("d"),
+			Status: store.ChunkFailed, Note: sql.NullString{String: "abort", Valid: true},
+			Timestamp: time.Now().Unix()},
+	}
+	for _, c := range chunks {
+		assert.NilError(t, db.InsertSubmittedChunk(ctx, c))
+	}
+
+	listed, err := db.ListSubmittedChunks(ctx, 10)
+	assert.NilError(t, err)
+	assert.Equal(t, len(listed), 2)
+	assert.Equal(t, listed[0].FromHeight, int64(110))
+	assert.Equal(t, listed[0].Note.String, "abort")
+	assert.Equal(t, listed[1].TxDigest, "digest1")
+
+	last, err := db.GetLastSubmittedChunk(ctx, store.ChunkSubmitted)
+	assert.NilError(t, err)
+	assert.Equal(t, last.ToHeight, int64(109))
+
+	db2 := storetest.InitTestDB(ctx, t)
+	last, err = db2.GetLastSubmittedChunk(ctx, store.ChunkSubmitted)
+	assert.NilError(t, err)
+	assert.Assert(t, last == nil)
+}
+
+func Test_UpsertCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	cp, err := db.GetCheckpoint(ctx, store.CheckpointLightClient)
+	assert.NilError(t, err)
+	assert.Assert(t, cp == nil)
+
+	first := store.Checkpoint{
+		Name: store.CheckpointLightClient, Height: 100, BlockHash: []byte("hash100"), Timestamp: 1,
+	}
+	assert.NilError(t, db.UpsertCheckpoint(ctx, first))
+	cp, err = db.GetCheckpoint(ctx, store.CheckpointLightClient)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cp, &first)
+
+	second := store.Checkpoint{
+		Name: store.CheckpointLightClient, Height: 105, BlockHash: []byte("hash105"), Timestamp: 2,
+	}
+	assert.NilError(t, db.UpsertCheckpoint(ctx, second))
+	cp, err = db.GetCheckpoint(ctx, store.CheckpointLightClient)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cp, &second)
+
+	cp, err = db.GetCheckpoint(ctx, store.CheckpointWalrus)
+	assert.NilError(t, err)
+	assert.Assert(t, cp == nil)
+}
+
+func Test_AdvanceCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	first := store.Checkpoint{Name: store.CheckpointWalrus, Height: 105, BlockHash: []byte("hash105"), Timestamp: 1}
+	assert.NilError(t, db.AdvanceCheckpoint(ctx, first))
+	// a lower block doesn't move the checkpoint back
+	lower := store.Checkpoint{Name: store.CheckpointWalrus, Height: 103, BlockHash: []byte("hash103"), Timestamp: 2}
+	assert.NilError(t, db.AdvanceCheckpoint(ctx, lower))
+	cp, err := db.GetCheckpoint(ctx, store.CheckpointWalrus)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cp, &first)
+
+	higher := store.Checkpoint{Name: store.CheckpointWalrus, Height: 106, BlockHash: []byte("hash106"), Timestamp: 3}
+	assert.NilError(t, db.AdvanceCheckpoint(ctx, higher))
+	cp, err = db.GetCheckpoint(ctx, store.CheckpointWalrus)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cp, &higher)
+}
+
+func Test_WalrusBlobs(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	blob, err := db.GetWalrusBlob(ctx, []byte("hash100"))
+	assert.NilError(t, err)
+	assert.Assert(t, blob == nil)
+	maxCost, err := db.GetMaxWalrusBlobCost(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, maxCost, int64(0))
+
+	blobs := []store.WalrusBlob{
+		{BlockHash: []byte("hash100"), Height: 100, BlobID: "blob100", Cost: 10, EndEpoch: 5, Timestamp: 1},
+		{BlockHash: []byte("hash101"), Height: 101, BlobID: "blob101", Cost: 11, EndEpoch: 5, Timestamp: 2},
+		// reorg: another block at height 101
+		{BlockHash: []byte("hash101b"), Height: 101, BlobID: "blob101b", Cost: 0, EndEpoch: 6, Timestamp: 3},
+		{BlockHash: []byte("hash102"), Height: 102, BlobID: "blob102", Cost: 12, EndEpoch: 6, Timestamp: 4},
+	}
+	for _, b := range blobs {
+		assert.NilError(t, db.UpsertWalrusBlob(ctx, b))
+	}
+
+	blob, err = db.GetWalrusBlob(ctx, []byte("hash101"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, blob, &blobs[1])
+
+	listed, err := db.ListWalrusBlobs(ctx, 101, 101)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, listed, []*store.WalrusBlob{&blobs[1], &blobs[2]})
+
+	listed, err = db.ListWalrusBlobs(ctx, 100, 200)
+	assert.NilError(t, err)
+	assert.Equal(t, len(listed), 4)
+
+	// storing a block again replaces its blob
+	updated := blobs[0]
+	updated.BlobID, updated.EndEpoch, updated.Timestamp = "blob100b", 8, 5
+	assert.NilError(t, db.UpsertWalrusBlob(ctx, updated))
+	blob, err = db.GetWalrusBlob(ctx, []byte("hash100"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, blob, &updated)
+
+	maxCost, err = db.GetMaxWalrusBlobCost(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, maxCost, int64(12))
+
+	expiring, err := db.ListExpiringWalrusBlobs(ctx, 6, 101)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, expiring, []*store.WalrusBlob{&blobs[1], &blobs[2], &blobs[3]})
+	expiring, err = db.ListExpiringWalrusBlobs(ctx, 5, 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, expiring, []*store.WalrusBlob{&blobs[1]})
+
+	assert.NilError(t, db.DeleteWalrusBlob(ctx, []byte("hash101")))
+	listed, err = db.ListWalrusBlobs(ctx, 101, 101)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, listed, []*store.WalrusBlob{&blobs[2]})
+}
+
+func Test_WalrusEpochs(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	epoch, err := db.GetLatestWalrusEpoch(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, epoch == nil)
+
+	assert.NilError(t, db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: 4, Timestamp: 10}))
+	assert.NilError(t, db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: 5, Timestamp: 20, RenewCost: 7}))
+	// recording an epoch again adds the renew cost and keeps its timestamp
+	assert.NilError(t, db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: 5, Timestamp: 30, RenewCost: 3}))
+
+	epoch, err = db.GetLatestWalrusEpoch(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, epoch, &store.WalrusEpoch{Epoch: 5, Timestamp: 20, RenewCost: 10})
+
+	epoch, err = db.GetWalrusEpoch(ctx, 4)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, epoch, &store.WalrusEpoch{Epoch: 4, Timestamp: 10})
+	epoch, err = db.GetWalrusEpoch(ctx, 6)
+	assert.NilError(t, err)
+	assert.Assert(t, epoch == nil)
+}
+
+func Test_WalrusUploads(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+
+	uploads, err := db.ListWalrusUploads(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(uploads), 0)
+
+	assert.NilError(t, db.InsertWalrusUpload(ctx, []byte("hash101"), 101))
+	assert.NilError(t, db.InsertWalrusUpload(ctx, []byte("hash100"), 100))
+	assert.NilError(t, db.UpdateWalrusUploadAttempt(ctx, []byte("hash100"), 2, "publisher unavailable"))
+	// queuing a block again keeps its attempts
+	assert.NilError(t, db.InsertWalrusUpload(ctx, []byte("hash100"), 100))
+
+	uploads, err = db.ListWalrusUploads(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(uploads), 2)
+	assert.DeepEqual(t, uploads[0].BlockHash, []byte("hash100"))
+	assert.Equal(t, uploads[0].Attempts, int64(2))
+	assert.Equal(t, uploads[0].LastError.String, "publisher unavailable")
+	assert.Equal(t, uploads[1].Height, int64(101))
+	assert.Equal(t, uploads[1].LastError.Valid, false)
+
+	assert.NilError(t, db.DeleteWalrusUpload(ctx, []byte("hash100")))
+	uploads, err = db.ListWalrusUploads(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(uploads), 1)
+	assert.Equal(t, uploads[0].Height, int64(101))
+}
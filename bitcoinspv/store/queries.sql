@@ -0,0 +1,102 @@
+-- name: InsertSubmittedChunk :exec
+INSERT INTO submitted_chunks (from_height, to_height, first_hash, last_hash, tx_digest, status, note, timestamp)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListSubmittedChunks :many
+SELECT id, from_height, to_height, first_hash, last_hash, tx_digest, status, note, timestamp
+FROM submitted_chunks
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: GetLastSubmittedChunk :one
+SELECT id, from_height, to_height, first_hash, last_hash, tx_digest, status, note, timestamp
+FROM submitted_chunks
+WHERE status = ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: UpsertCheckpoint :exec
+INSERT INTO checkpoints (name, height, block_hash, timestamp)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE
+SET height = excluded.height, block_hash = excluded.block_hash, timestamp = excluded.timestamp;
+
+-- name: AdvanceCheckpoint :exec
+INSERT INTO checkpoints (name, height, block_hash, timestamp)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE
+SET height = excluded.height, block_hash = excluded.block_hash, timestamp = excluded.timestamp
+WHERE excluded.height >= checkpoints.height;
+
+-- name: GetCheckpoint :one
+SELECT name, height, block_hash, timestamp
+FROM checkpoints
+WHERE name = ?;
+
+-- name: UpsertWalrusBlob :exec
+INSERT INTO walrus_blobs (block_hash, height, blob_id, cost, end_epoch, timestamp)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (block_hash) DO UPDATE
+SET height = excluded.height, blob_id = excluded.blob_id, cost = excluded.cost,
+    end_epoch = excluded.end_epoch, timestamp = excluded.timestamp;
+
+-- name: GetWalrusBlob :one
+SELECT block_hash, height, blob_id, cost, end_epoch, timestamp
+FROM walrus_blobs
+WHERE block_hash = ?;
+
+-- name: DeleteWalrusBlob :exec
+DELETE FROM walrus_blobs
+WHERE block_hash = ?;
+
+-- name: ListWalrusBlobs :many
+SELECT block_hash, height, blob_id, cost, end_epoch, timestamp
+FROM walrus_blobs
+WHERE height BETWEEN sqlc.arg(from_height) AND sqlc.arg(to_height)
+ORDER BY height, timestamp;
+
+-- name: InsertWalrusUpload :exec
+INSERT INTO walrus_uploads (block_hash, height, attempts, timestamp)
+VALUES (?, ?, 0, ?)
+ON CONFLICT (block_hash) DO NOTHING;
+
+-- name: UpdateWalrusUploadAttempt :exec
+UPDATE walrus_uploads
+SET attempts = ?, last_error = ?
+WHERE block_hash = ?;
+
+-- name: DeleteWalrusUpload :exec
+DELETE FROM walrus_uploads
+WHERE block_hash = ?;
+
+-- name: ListWalrusUploads :many
+SELECT block_hash, height, attempts, last_error, timestamp
+FROM walrus_uploads
+ORDER BY height;
+
+-- name: ListExpiringWalrusBlobs :many
+SELECT block_hash, height, blob_id, cost, end_epoch, timestamp
+FROM walrus_blobs
+WHERE end_epoch <= sqlc.arg(max_end_epoch) AND height >= sqlc.arg(from_height)
+ORDER BY end_epoch, height;
+
+-- name: UpsertWalrusEpoch :exec
+INSERT INTO walrus_epochs (epoch, timestamp, renew_cost)
+VALUES (?, ?, ?)
+ON CONFLICT (epoch) DO UPDATE
+SET renew_cost = walrus_epochs.renew_cost + excluded.renew_cost;
+
+-- name: GetWalrusEpoch :one
+SELECT epoch, timestamp, renew_cost
+FROM walrus_epochs
+WHERE epoch = ?;
+
+-- name: GetLatestWalrusEpoch :one
+SELECT epoch, timestamp, renew_cost
+FROM walrus_epochs
+ORDER BY epoch DESC
+LIMIT 1;
+
+-- name: GetMaxWalrusBlobCost :one
+SELECT CAST(COALESCE(MAX(cost), 0) AS INTEGER) AS max_cost
+FROM walrus_blobs;
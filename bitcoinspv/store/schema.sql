@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS submitted_chunks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_height INTEGER NOT NULL,
+    to_height INTEGER NOT NULL,
+    first_hash BLOB NOT NULL,
+    last_hash BLOB NOT NULL,
+    tx_digest TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    note TEXT,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+    name TEXT PRIMARY KEY,
+    height INTEGER NOT NULL,
+    block_hash BLOB NOT NULL,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS walrus_blobs (
+    block_hash BLOB PRIMARY KEY,
+    height INTEGER NOT NULL,
+    blob_id TEXT NOT NULL,
+    cost INTEGER NOT NULL,
+    end_epoch INTEGER NOT NULL,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS walrus_blobs_height ON walrus_blobs (height);
+
+CREATE TABLE IF NOT EXISTS walrus_uploads (
+    block_hash BLOB PRIMARY KEY,
+    height INTEGER NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS walrus_epochs (
+    epoch INTEGER PRIMARY KEY,
+    timestamp INTEGER NOT NULL,
+    renew_cost INTEGER NOT NULL
+);
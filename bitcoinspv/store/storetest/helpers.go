@@ -0,0 +1,28 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"gotest.tools/v3/assert"
+)
+
+// testDBCounter makes every InitTestDB call use a fresh shared-cache in-memory DB.
+// See daltest.InitTestDB for the rationale.
+var testDBCounter = 0
+
+// InitTestDB initializes an in-memory database for testing purposes. Subsequent calls will
+// create a new in-memory DB.
+func InitTestDB(ctx context.Context, t *testing.T) *store.DB {
+	t.Helper()
+
+	testDBCounter++
+	db, err := store.NewDB(fmt.Sprintf("file:spvdb%d?mode=memory&cache=shared", testDBCounter))
+	assert.NilError(t, err)
+	err = db.InitDB(ctx)
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
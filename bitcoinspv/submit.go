@@ -3,8 +3,13 @@ package bitcoinspv
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
 	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // createChunks takes a set of indexed blocks and breaks them into chunks of headers to be sent
@@ -22,33 +27,96 @@ func (r *Relayer) createChunks(ctx context.Context, indexedBlocks []*types.Index
 	}
 
 	blocksToSubmit := indexedBlocks[startPoint:]
+	if err := r.validateHeaders(blocksToSubmit); err != nil {
+		return nil, err
+	}
+	ok, err := r.canBecomeBestChain(ctx, blocksToSubmit)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
 	blockChunks := breakIntoChunks(blocksToSubmit, int(r.Config.HeadersChunkSize))
 	return blockChunks, nil
 }
 
+// validateHeaders checks the headers against the Bitcoin consensus rules, so we don't pay gas
+// for headers the light client would reject. It's a no-op when validation is disabled.
+func (r *Relayer) validateHeaders(blocks []*types.IndexedBlock) error {
+	if r.headerValidator == nil || len(blocks) == 0 {
+		return nil
+	}
+	return r.headerValidator.ValidateHeaders(blocks[0].BlockHeight, toBlockHeaders(blocks))
+}
+
+// searchProbes is the number of headers checked with a single ContainsBlocks call
+// by FindFirstUnknownHeaderIndex.
+const searchProbes = 16
+
 // FindFirstUnknownHeaderIndex finds the index of the first header not present in the light client.
+// Blocks are consecutive, so once a header is unknown to the light client all the following
+// headers are unknown too. The index is found with a k-ary search: each step checks
+// searchProbes evenly spaced headers in a single ContainsBlocks call and narrows the search
+// range to the interval between the last known and the first unknown probe.
+// Returns -1 when all headers are known.
 func (r *Relayer) FindFirstUnknownHeaderIndex(ctx context.Context, indexedBlocks []*types.IndexedBlock) (int, error) {
-	for i, header := range indexedBlocks {
-		blockHash := header.BlockHash()
-		var res bool
-		var err error
-		err = RetryDo(r.logger, r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration, func() error {
-			res, err = r.lcClient.ContainsBlock(ctx, blockHash)
+	// invariant: headers before lo are known, headers from hi are unknown
+	lo, hi := 0, len(indexedBlocks)
+	for lo < hi {
+		n := min(searchProbes, hi-lo)
+		probes := make([]int, n)
+		hashes := make([]chainhash.Hash, n)
+		for i := range probes {
+			probes[i] = lo + (hi-lo)*i/n
+			hashes[i] = indexedBlocks[probes[i]].BlockHash()
+		}
+
+		var known []bool
+		err := RetryDo(r.logger, r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration, func() error {
+			var err error
+			known, err = r.lcClient.ContainsBlocks(ctx, hashes)
 			return err
 		})
 		if err != nil {
 			return -1, err
 		}
-		if !res {
-			return i, nil
+		if len(known) != n {
+			return -1, fmt.Errorf("light client returned %d results for %d headers", len(known), n)
+		}
+
+		first := slices.Index(known, false)
+		if first == -1 {
+			lo = probes[n-1] + 1
+			continue
+		}
+		hi = probes[first]
+		if first > 0 {
+			lo = probes[first-1] + 1
 		}
 	}
-	return -1, nil
+
+	if lo == len(indexedBlocks) {
+		return -1, nil
+	}
+	return lo, nil
+}
+
+// SetDryRun tells the relayer that the light client dev-inspects the header
+// transactions instead of executing them. The chunks are then not recorded in the state DB
+// and don't move the light client checkpoint and metrics.
+func (r *Relayer) SetDryRun(dryRun bool) {
+	r.dryRun = dryRun
 }
 
-func (r *Relayer) submitHeaderMessages(ctx context.Context, chunk Chunk) error {
+func (r *Relayer) submitHeaderMessages(ctx context.Context, chunk Chunk) (string, error) {
+	var txDigest string
 	err := RetryDo(r.logger, r.Config.RetrySleepDuration, r.Config.MaxRetrySleepDuration, func() error {
-		if err := r.lcClient.InsertHeaders(ctx, chunk.Headers); err != nil {
+		var err error
+		timer := prometheus.NewTimer(metrics.InsertHeadersDuration)
+		txDigest, err = r.lcClient.InsertHeaders(ctx, chunk.Headers)
+		timer.ObserveDuration()
+		if err != nil {
 			return err
 		}
 		hs := chunk.Headers
@@ -61,19 +129,79 @@ func (r *Relayer) submitHeaderMessages(ctx context.Context, chunk Chunk) error {
 		} else {
 			headersStr = fmt.Sprint("header=", firstHash, " height=", chunk.From)
 		}
-		r.logger.Info().Msgf("Submitted %d %s to light client", len(hs), headersStr)
+		r.logger.Info().Str("tx_digest", txDigest).Msgf("Submitted %d %s to light client", len(hs), headersStr)
 		return nil
 	})
+	if !r.dryRun {
+		r.recordChunk(ctx, chunk, txDigest, err)
+	}
+	r.state.submitted(err)
+	if err != nil {
+		metrics.ChunksFailed.Inc()
+		return "", fmt.Errorf("failed to submit headers [%d, %d]: %w", chunk.From, chunk.To, err)
+	}
+	if r.dryRun {
+		// the light client didn't move
+		return txDigest, nil
+	}
+	metrics.HeadersSubmitted.Add(float64(len(chunk.Headers)))
+	metrics.SetLCTip(chunk.To)
+	return txDigest, nil
+}
+
+// SubmittedChunk is a chunk of headers submitted to the light client.
+type SubmittedChunk struct {
+	From     int64  `json:"from"`
+	To       int64  `json:"to"`
+	TxDigest string `json:"tx_digest"`
+}
+
+// submitChunks submits the chunks in order and stops at the first failure.
+// Returns the chunks submitted before the failure.
+func (r *Relayer) submitChunks(ctx context.Context, chunks []Chunk) ([]SubmittedChunk, error) {
+	submitted := make([]SubmittedChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		txDigest, err := r.submitHeaderMessages(ctx, chunk)
+		if err != nil {
+			return submitted, err
+		}
+		submitted = append(submitted, SubmittedChunk{From: chunk.From, To: chunk.To, TxDigest: txDigest})
+	}
+	return submitted, nil
+}
+
+// SubmitRange submits the headers of the Bitcoin node between the from and to heights
+// (included) to the light client, to fill gaps without restarting the relayer. Headers
+// already known to the light client are skipped. The leader election is not checked.
+// Returns the submitted chunks, including the ones submitted before a failure.
+func (r *Relayer) SubmitRange(ctx context.Context, from, to int64) ([]SubmittedChunk, error) {
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid height range [%d, %d]", from, to)
+	}
+	blocks := make([]*types.IndexedBlock, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		header, err := r.btcClient.GetBTCBlockHeaderByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header at height %d: %w", height, err)
+		}
+		blocks = append(blocks, types.NewIndexedBlock(height, wire.NewMsgBlock(header)))
+	}
+
+	chunks, err := r.createChunks(ctx, blocks)
 	if err != nil {
-		return fmt.Errorf("failed to submit headers: %w", err)
+		return nil, fmt.Errorf("failed to find headers to submit: %w", err)
 	}
-	return nil
+	return r.submitChunks(ctx, chunks)
 }
 
 // ProcessHeaders takes a list of blocks, extracts their headers
 // and submits them to the light client.
 // Returns the count of unique headers that were submitted.
 func (r *Relayer) ProcessHeaders(ctx context.Context, indexedBlocks []*types.IndexedBlock) (int, error) {
+	if !r.isLeader() {
+		r.logger.Debug().Msg("Standby instance, skipping headers submission")
+		return 0, nil
+	}
 	chunks, err := r.createChunks(ctx, indexedBlocks)
 	if err != nil {
 		return 0, fmt.Errorf("failed to find headers to submit: %w", err)
@@ -82,12 +210,13 @@ func (r *Relayer) ProcessHeaders(ctx context.Context, indexedBlocks []*types.Ind
 		r.logger.Debug().Msg("No new headers to submit")
 	}
 
+	submitted, err := r.submitChunks(ctx, chunks)
+	if err != nil {
+		return 0, err
+	}
 	headersSubmitted := 0
-	for _, chunk := range chunks {
-		if err := r.submitHeaderMessages(ctx, chunk); err != nil {
-			return 0, fmt.Errorf("failed to submit headers: %w", err)
-		}
-		headersSubmitted += len(chunk.Headers)
+	for _, chunk := range submitted {
+		headersSubmitted += int(chunk.To - chunk.From + 1)
 	}
 
 	return headersSubmitted, nil
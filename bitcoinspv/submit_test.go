@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients/mocks"
 	sui_errors "github.com/gonative-cc/relayer/bitcoinspv/clients/sui"
 	"github.com/gonative-cc/relayer/bitcoinspv/config"
 	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var testSubmitConfig = &config.RelayerConfig{
@@ -28,9 +33,34 @@ var testSubmitConfig = &config.RelayerConfig{
 	ProcessBlockTimeout:   5 * time.Second,
 }
 
+// knownPrefix returns a ContainsBlocks implementation reporting the first n blocks
+// as known to the light client.
+func knownPrefix(blocks []*types.IndexedBlock, n int) func(context.Context, []chainhash.Hash) ([]bool, error) {
+	known := make(map[chainhash.Hash]bool, n)
+	for _, b := range blocks[:n] {
+		known[b.BlockHash()] = true
+	}
+	return func(_ context.Context, hashes []chainhash.Hash) ([]bool, error) {
+		res := make([]bool, len(hashes))
+		for i, h := range hashes {
+			res[i] = known[h]
+		}
+		return res, nil
+	}
+}
+
+func blockHashes(blocks []*types.IndexedBlock) []chainhash.Hash {
+	hashes := make([]chainhash.Hash, len(blocks))
+	for i, b := range blocks {
+		hashes[i] = b.BlockHash()
+	}
+	return hashes
+}
+
 func TestFindFirstUnknownHeaderIndex(t *testing.T) {
 	ctx := context.Background()
 	testBlocks := types.CreateTestIndexedBlocks(t, 5, 100) // heights 100, 101, 102, 103, 104
+	hashes := blockHashes(testBlocks)
 
 	tests := []struct {
 		name          string
@@ -42,17 +72,15 @@ func TestFindFirstUnknownHeaderIndex(t *testing.T) {
 		{
 			name: "all headers exist",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				for _, b := range testBlocks {
-					mockLC.On("ContainsBlock", ctx, b.BlockHash()).Return(true, nil).Once()
-				}
+				mockLC.On("ContainsBlocks", ctx, hashes).Return([]bool{true, true, true, true, true}, nil).Once()
 			},
 			expectedIndex: -1,
-			expectedCalls: 5,
+			expectedCalls: 1,
 		},
 		{
 			name: "no headers exist",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, hashes).Return([]bool{false, false, false, false, false}, nil).Once()
 			},
 			expectedIndex: 0,
 			expectedCalls: 1,
@@ -60,35 +88,40 @@ func TestFindFirstUnknownHeaderIndex(t *testing.T) {
 		{
 			name: "some headers exist",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[1].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, hashes).Return([]bool{true, true, false, false, false}, nil).Once()
 			},
 			expectedIndex: 2,
-			expectedCalls: 3,
+			expectedCalls: 1,
 		},
 		{
-			name: "ContainsBlock retryable error then success (finds new)",
+			name: "ContainsBlocks retryable error then success (finds new)",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				retryableErr := errors.New("network timeout")
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[1].BlockHash()).Return(false, retryableErr).Once() // retry after error
-				mockLC.On("ContainsBlock", ctx, testBlocks[1].BlockHash()).Return(true, nil).Once()           // success on retry
-				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, hashes).Return(nil, retryableErr).Once() // retry after error
+				mockLC.On("ContainsBlocks", ctx, hashes).Return([]bool{true, true, false, false, false}, nil).Once()
 			},
 			expectedIndex: 2,
-			expectedCalls: 4,
+			expectedCalls: 2,
 		},
 		{
-			name: "ContainsBlock abort error",
+			name: "ContainsBlocks abort error",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				abortErr := fmt.Errorf("%w: ... MoveAbort(...)", sui_errors.ErrSuiTransactionFailed)
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, abortErr).Once()
+				mockLC.On("ContainsBlocks", ctx, hashes).Return(nil, abortErr).Once()
 			},
 			expectedIndex: -1,
 			expectedCalls: 1,
 			expectedErr:   fmt.Errorf("%w: ... MoveAbort(...)", sui_errors.ErrSuiTransactionFailed),
 		},
+		{
+			name: "wrong number of results",
+			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
+				mockLC.On("ContainsBlocks", ctx, hashes).Return([]bool{true}, nil).Once()
+			},
+			expectedIndex: -1,
+			expectedCalls: 1,
+			expectedErr:   errors.New("light client returned 1 results for 5 headers"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -110,11 +143,32 @@ func TestFindFirstUnknownHeaderIndex(t *testing.T) {
 				assert.Equal(t, tt.expectedIndex, idx)
 			}
 			mockLC.AssertExpectations(t)
-			mockLC.AssertNumberOfCalls(t, "ContainsBlock", tt.expectedCalls)
+			mockLC.AssertNumberOfCalls(t, "ContainsBlocks", tt.expectedCalls)
 		})
 	}
 }
 
+func TestFindFirstUnknownHeaderIndexLargeCache(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 1000, 100)
+
+	for _, known := range []int{0, 1, 15, 16, 500, 637, 999, 1000} {
+		mockLC := mocks.NewMockBitcoinSPV(t)
+		mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, known))
+		r := &Relayer{lcClient: mockLC, logger: zerolog.Nop(), Config: testSubmitConfig}
+
+		idx, err := r.FindFirstUnknownHeaderIndex(ctx, testBlocks)
+		assert.NoError(t, err)
+		expected := known
+		if known == len(testBlocks) {
+			expected = -1
+		}
+		assert.Equal(t, expected, idx, "known headers: %d", known)
+		// 16 probes per call: 1000 -> 63 -> 4 -> 1
+		assert.LessOrEqual(t, len(mockLC.Calls), 4, "known headers: %d", known)
+	}
+}
+
 func TestCreateChunks(t *testing.T) {
 	ctx := context.Background()
 	testBlocks := types.CreateTestIndexedBlocks(t, 5, 100) // heights 100, 101, 102, 103, 104
@@ -127,25 +181,23 @@ func TestCreateChunks(t *testing.T) {
 		{
 			name: "all headers new",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[0])
 			},
 			expectedChunkLen: []int{2, 2, 1}, // {100, 101}{102, 103}{104}
 		},
 		{
 			name: "some headers new (index 2)",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[1].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 2)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[2])
 			},
 			expectedChunkLen: []int{2, 1}, // {102, 103}{104}
 		},
 		{
 			name: "all headers exist",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				for _, b := range testBlocks {
-					mockLC.On("ContainsBlock", ctx, b.BlockHash()).Return(true, nil).Once()
-				}
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 5)).Once()
 			},
 			expectedChunkLen: nil,
 		},
@@ -200,7 +252,7 @@ func TestSubmitHeaderMessages(t *testing.T) {
 		{
 			name: "success on first try",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(nil).Once()
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("digest", nil).Once()
 			},
 			expectedErr:   false,
 			expectedCalls: 1,
@@ -208,8 +260,8 @@ func TestSubmitHeaderMessages(t *testing.T) {
 		{
 			name: "retryable error then success",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(retryableErr).Once()
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(nil).Once()
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("", retryableErr).Once()
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("digest", nil).Once()
 			},
 			expectedErr:   false,
 			expectedCalls: 2,
@@ -217,7 +269,7 @@ func TestSubmitHeaderMessages(t *testing.T) {
 		{
 			name: "non-retryable MoveAbort error",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(nonRetryableMoveAbortErr).Once()
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("", nonRetryableMoveAbortErr).Once()
 			},
 			expectedErr:   true,
 			expectedCalls: 1,
@@ -225,7 +277,7 @@ func TestSubmitHeaderMessages(t *testing.T) {
 		{
 			name: "non-retryable OutOfGas error",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(nonRetryableOutOfGasErr).Once()
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("", nonRetryableOutOfGasErr).Once()
 			},
 			expectedErr:   true,
 			expectedCalls: 1,
@@ -234,7 +286,7 @@ func TestSubmitHeaderMessages(t *testing.T) {
 			name: "retryable error timeout",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// This simulates RetryDo hitting timeout
-				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return(retryableErr)
+				mockLC.On("InsertHeaders", ctx, testChunk.Headers).Return("", retryableErr)
 			},
 			expectedErr:   true,
 			expectedCalls: -1,
@@ -250,7 +302,7 @@ func TestSubmitHeaderMessages(t *testing.T) {
 				logger:   zerolog.Nop(),
 				Config:   testSubmitConfig,
 			}
-			err := r.submitHeaderMessages(ctx, testChunk)
+			_, err := r.submitHeaderMessages(ctx, testChunk)
 			if tt.expectedErr {
 				assert.Error(t, err)
 			} else {
@@ -281,11 +333,12 @@ func TestProcessHeaders(t *testing.T) {
 			name: "success - all headers new",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// findFirstNewHeader finds index 0
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[0])
 				// then submitHeaderMessages calls InsertHeaders
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return(nil).Once()
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return(nil).Once()
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk3).Return(nil).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return("digest", nil).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return("digest", nil).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk3).Return("digest", nil).Once()
 			},
 			expectedCount: 5,
 			expectedErr:   false,
@@ -294,12 +347,11 @@ func TestProcessHeaders(t *testing.T) {
 			name: "success - some headers new",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// findFirstNewHeader finds index 2
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[1].BlockHash()).Return(true, nil).Once()
-				mockLC.On("ContainsBlock", ctx, testBlocks[2].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 2)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[2])
 				// then submitHeaderMessages calls InsertHeaders for chunks 2 and 3
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return(nil).Once() // 102, 103
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk3).Return(nil).Once() // 104
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return("digest", nil).Once() // 102, 103
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk3).Return("digest", nil).Once() // 104
 			},
 			expectedCount: 3,
 			expectedErr:   false,
@@ -308,9 +360,7 @@ func TestProcessHeaders(t *testing.T) {
 			name: "no new headers",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// findFirstNewHeader finds index -1
-				for _, b := range testBlocks {
-					mockLC.On("ContainsBlock", ctx, b.BlockHash()).Return(true, nil).Once()
-				}
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 5)).Once()
 				// InsertHeaders should not be called
 			},
 			expectedCount: 0,
@@ -320,10 +370,11 @@ func TestProcessHeaders(t *testing.T) {
 			name: "error first chunk",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// findFirstNewHeader finds index 0
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[0])
 				// then submitHeaderMessages calls InsertHeaders and fails for chunk 1
 				submitErr := fmt.Errorf("%w: ... MoveAbort(...)", sui_errors.ErrSuiTransactionFailed)
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return(submitErr).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return("", submitErr).Once()
 				// InsertHeaders should not be called after err
 			},
 			expectedCount: 0,
@@ -333,12 +384,13 @@ func TestProcessHeaders(t *testing.T) {
 			name: "error second chunk",
 			mockSetup: func(mockLC *mocks.MockBitcoinSPV) {
 				// findFirstNewHeader finds index 0
-				mockLC.On("ContainsBlock", ctx, testBlocks[0].BlockHash()).Return(false, nil).Once()
+				mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+				mockLCHead(ctx, mockLC, testBlocks[0])
 				// then succeeds for first chunk
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return(nil).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk1).Return("digest", nil).Once()
 				// then fails for second chunk
 				submitErr := fmt.Errorf("%w: ... MoveAbort(...)", sui_errors.ErrSuiTransactionFailed)
-				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return(submitErr).Once()
+				mockLC.On("InsertHeaders", ctx, expectedHeadersChunk2).Return("", submitErr).Once()
 				// InsertHeaders should not be called after err
 			},
 			expectedCount: 0,
@@ -370,3 +422,83 @@ func TestProcessHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessHeadersInvalidHeaders(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 3, 100) // headers without proof-of-work
+
+	mockLC := mocks.NewMockBitcoinSPV(t)
+	mockLC.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+	// InsertHeaders must not be called
+
+	r := &Relayer{
+		lcClient:        mockLC,
+		logger:          zerolog.Nop(),
+		Config:          testSubmitConfig,
+		headerValidator: btctypes.NewHeaderValidator(&chaincfg.RegressionNetParams, mocks.NewMockBTCClient(t)),
+	}
+
+	count, err := r.ProcessHeaders(ctx, testBlocks)
+	assert.ErrorIs(t, err, btctypes.ErrInvalidHeader)
+	assert.Equal(t, 0, count)
+}
+
+func TestSubmitRange(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 5, 100) // heights 100...104
+
+	setup := func(t *testing.T) (*Relayer, *mocks.MockBTCClient, *mocks.MockBitcoinSPV) {
+		btcClient := mocks.NewMockBTCClient(t)
+		lcClient := mocks.NewMockBitcoinSPV(t)
+		for _, b := range testBlocks {
+			btcClient.On("GetBTCBlockHeaderByHeight", b.BlockHeight).Return(&b.MsgBlock.Header, nil).Maybe()
+		}
+		return &Relayer{btcClient: btcClient, lcClient: lcClient, logger: zerolog.Nop(), Config: testSubmitConfig},
+			btcClient, lcClient
+	}
+
+	t.Run("skips known headers", func(t *testing.T) {
+		r, _, lcClient := setup(t)
+		lcClient.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 2)).Once()
+		mockLCHead(ctx, lcClient, testBlocks[2])
+		lcClient.On("InsertHeaders", ctx, toBlockHeaders(testBlocks[2:4])).Return("digest1", nil).Once()
+		lcClient.On("InsertHeaders", ctx, toBlockHeaders(testBlocks[4:])).Return("digest2", nil).Once()
+
+		submitted, err := r.SubmitRange(ctx, 100, 104)
+		assert.NoError(t, err)
+		assert.Equal(t, []SubmittedChunk{
+			{From: 102, To: 103, TxDigest: "digest1"},
+			{From: 104, To: 104, TxDigest: "digest2"},
+		}, submitted)
+	})
+
+	t.Run("all headers known", func(t *testing.T) {
+		r, _, lcClient := setup(t)
+		lcClient.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 3)).Once()
+
+		submitted, err := r.SubmitRange(ctx, 100, 102)
+		assert.NoError(t, err)
+		assert.Empty(t, submitted)
+	})
+
+	t.Run("chunk failure reports the submitted chunks", func(t *testing.T) {
+		r, _, lcClient := setup(t)
+		moveAbort := fmt.Errorf("%w: MoveAbort(...)", sui_errors.ErrSuiTransactionFailed)
+		lcClient.On("ContainsBlocks", ctx, mock.Anything).Return(knownPrefix(testBlocks, 0)).Once()
+		mockLCHead(ctx, lcClient, testBlocks[0])
+		lcClient.On("InsertHeaders", ctx, toBlockHeaders(testBlocks[:2])).Return("digest1", nil).Once()
+		lcClient.On("InsertHeaders", ctx, toBlockHeaders(testBlocks[2:4])).Return("", moveAbort).Once()
+
+		submitted, err := r.SubmitRange(ctx, 100, 104)
+		assert.ErrorIs(t, err, sui_errors.ErrSuiTransactionFailed)
+		assert.Equal(t, 1, strings.Count(err.Error(), "failed to submit headers"))
+		assert.ErrorContains(t, err, "[102, 103]")
+		assert.Equal(t, []SubmittedChunk{{From: 100, To: 101, TxDigest: "digest1"}}, submitted)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		r, _, _ := setup(t)
+		_, err := r.SubmitRange(ctx, 104, 100)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,23 @@
+package btc
+
+import (
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// CalcWork returns the expected number of hashes needed to mine a header with the
+// given compact difficulty target.
+func CalcWork(bits uint32) *big.Int {
+	return blockchain.CalcWork(bits)
+}
+
+// HeadersWork returns the total work of the headers.
+func HeadersWork(headers []wire.BlockHeader) *big.Int {
+	work := new(big.Int)
+	for i := range headers {
+		work.Add(work, CalcWork(headers[i].Bits))
+	}
+	return work
+}
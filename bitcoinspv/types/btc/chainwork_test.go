@@ -0,0 +1,28 @@
+package btc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcWork(t *testing.T) {
+	// mainnet genesis: 0x1d00ffff, work 0x100010001
+	assert.Equal(t, big.NewInt(0x100010001), CalcWork(chaincfg.MainNetParams.GenesisBlock.Header.Bits))
+	// regtest limit: 0x207fffff, work 2
+	assert.Equal(t, big.NewInt(2), CalcWork(chaincfg.RegressionNetParams.PowLimitBits))
+}
+
+func TestHeadersWork(t *testing.T) {
+	assert.Equal(t, big.NewInt(0), HeadersWork(nil))
+
+	headers := []wire.BlockHeader{
+		{Bits: chaincfg.MainNetParams.GenesisBlock.Header.Bits},
+		{Bits: chaincfg.RegressionNetParams.PowLimitBits},
+		{Bits: chaincfg.RegressionNetParams.PowLimitBits},
+	}
+	assert.Equal(t, big.NewInt(0x100010001+4), HeadersWork(headers))
+}
@@ -0,0 +1,204 @@
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// maxCachedHeaders bounds the number of ancestor headers kept by the HeaderValidator.
+// It's enough to cover two difficulty periods.
+const maxCachedHeaders = 2 * 2016
+
+var (
+	// ErrInvalidHeader is returned when a header doesn't pass the consensus checks.
+	ErrInvalidHeader = errors.New("invalid block header")
+	// ErrHeaderLinkage is returned when a header doesn't extend the previous header.
+	ErrHeaderLinkage = errors.New("header doesn't link to the previous header")
+)
+
+// HeaderSource provides the headers of the chain the validated headers extend.
+// It is implemented by the Bitcoin node clients.
+type HeaderSource interface {
+	GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, error)
+}
+
+// HeaderValidator checks Bitcoin headers against the consensus rules before they are
+// submitted to the light client:
+//   - proof-of-work against the target encoded in the header bits,
+//   - difficulty retarget rules of the network (including the testnet minimum difficulty
+//     and the regtest no-retarget rules),
+//   - timestamp after the median time of the past 11 blocks and not too far in the future,
+//   - linkage of the headers to their parents.
+//
+// Ancestors of the validated headers are fetched from the HeaderSource.
+type HeaderValidator struct {
+	params     *chaincfg.Params
+	source     HeaderSource
+	timeSource blockchain.MedianTimeSource
+
+	mu    sync.Mutex
+	cache map[int64]wire.BlockHeader
+}
+
+// NewHeaderValidator creates a HeaderValidator for the given network.
+func NewHeaderValidator(params *chaincfg.Params, source HeaderSource) *HeaderValidator {
+	return &HeaderValidator{
+		params:     params,
+		source:     source,
+		timeSource: blockchain.NewMedianTime(),
+		cache:      make(map[int64]wire.BlockHeader),
+	}
+}
+
+// ValidateHeaders validates a list of consecutive headers. The first header is at
+// firstHeight and must extend the source header at firstHeight-1.
+func (v *HeaderValidator) ValidateHeaders(firstHeight int64, headers []wire.BlockHeader) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ctx := &validationCtx{v: v, first: firstHeight, headers: headers}
+	for i := range headers {
+		header := &headers[i]
+		height := firstHeight + int64(i)
+		if err := v.validateHeader(ctx, header, height); err != nil {
+			return fmt.Errorf("%w at height %d (%s): %w", ErrInvalidHeader, height, header.BlockHash(), err)
+		}
+	}
+	return nil
+}
+
+func (v *HeaderValidator) validateHeader(ctx *validationCtx, header *wire.BlockHeader, height int64) error {
+	err := blockchain.CheckBlockHeaderSanity(header, v.params.PowLimit, v.timeSource, blockchain.BFNone)
+	if err != nil {
+		return err
+	}
+	if height == 0 {
+		return nil
+	}
+
+	idx := height - ctx.first
+	if idx > 0 && header.PrevBlock != ctx.headers[idx-1].BlockHash() {
+		return ErrHeaderLinkage
+	}
+
+	// #nosec G115 -- Bitcoin heights fit in int32
+	node := &headerNode{ctx: ctx, height: int32(height), header: *header}
+	prev := node.Parent()
+	if ctx.err != nil {
+		return ctx.err
+	}
+	err = blockchain.CheckBlockHeaderContext(header, prev, blockchain.BFNone, chainCtx{v.params}, true)
+	if ctx.err != nil {
+		return ctx.err
+	}
+	return err
+}
+
+// ancestor returns the source header at the given height. When expected is not nil, the
+// header must have the expected hash, otherwise the header is refetched (the cached one may
+// be from a stale branch) and ErrHeaderLinkage is returned on mismatch.
+func (v *HeaderValidator) ancestor(height int64, expected *chainhash.Hash) (*wire.BlockHeader, error) {
+	if h, ok := v.cache[height]; ok && (expected == nil || h.BlockHash() == *expected) {
+		return &h, nil
+	}
+
+	h, err := v.source.GetBTCBlockHeaderByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch header at height %d: %w", height, err)
+	}
+	if expected != nil && h.BlockHash() != *expected {
+		return nil, fmt.Errorf("%w: source header at height %d is %s, expected %s",
+			ErrHeaderLinkage, height, h.BlockHash(), expected)
+	}
+
+	if len(v.cache) >= maxCachedHeaders {
+		v.cache = make(map[int64]wire.BlockHeader)
+	}
+	v.cache[height] = *h
+	return h, nil
+}
+
+// validationCtx holds the headers being validated. Errors from fetching ancestors can't be
+// returned through the blockchain.HeaderCtx interface, so they are kept in err.
+type validationCtx struct {
+	v       *HeaderValidator
+	first   int64
+	headers []wire.BlockHeader
+	err     error
+}
+
+// node returns the header at the given height, expected is the hash of the header
+// when known.
+func (c *validationCtx) node(height int64, expected *chainhash.Hash) blockchain.HeaderCtx {
+	if height < 0 || c.err != nil {
+		return nil
+	}
+	if idx := height - c.first; idx >= 0 && idx < int64(len(c.headers)) {
+		// #nosec G115 -- Bitcoin heights fit in int32
+		return &headerNode{ctx: c, height: int32(height), header: c.headers[idx]}
+	}
+
+	header, err := c.v.ancestor(height, expected)
+	if err != nil {
+		c.err = err
+		return nil
+	}
+	// #nosec G115 -- Bitcoin heights fit in int32
+	return &headerNode{ctx: c, height: int32(height), header: *header}
+}
+
+// headerNode implements blockchain.HeaderCtx.
+type headerNode struct {
+	ctx    *validationCtx
+	height int32
+	header wire.BlockHeader
+}
+
+var _ blockchain.HeaderCtx = &headerNode{}
+
+func (n *headerNode) Height() int32 { return n.height }
+
+func (n *headerNode) Bits() uint32 { return n.header.Bits }
+
+func (n *headerNode) Timestamp() int64 { return n.header.Timestamp.Unix() }
+
+func (n *headerNode) Parent() blockchain.HeaderCtx {
+	prevHash := n.header.PrevBlock
+	return n.ctx.node(int64(n.height)-1, &prevHash)
+}
+
+func (n *headerNode) RelativeAncestorCtx(distance int32) blockchain.HeaderCtx {
+	return n.ctx.node(int64(n.height-distance), nil)
+}
+
+// chainCtx implements blockchain.ChainCtx. Checkpoints are not verified.
+type chainCtx struct {
+	params *chaincfg.Params
+}
+
+var _ blockchain.ChainCtx = chainCtx{}
+
+func (c chainCtx) ChainParams() *chaincfg.Params { return c.params }
+
+func (c chainCtx) BlocksPerRetarget() int32 {
+	return int32(c.params.TargetTimespan / c.params.TargetTimePerBlock)
+}
+
+func (c chainCtx) MinRetargetTimespan() int64 {
+	return int64(c.params.TargetTimespan/time.Second) / c.params.RetargetAdjustmentFactor
+}
+
+func (c chainCtx) MaxRetargetTimespan() int64 {
+	return int64(c.params.TargetTimespan/time.Second) * c.params.RetargetAdjustmentFactor
+}
+
+func (c chainCtx) VerifyCheckpoint(int32, *chainhash.Hash) bool { return true }
+
+func (c chainCtx) FindPreviousCheckpoint() (blockchain.HeaderCtx, error) { return nil, nil }
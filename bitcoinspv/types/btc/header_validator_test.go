@@ -0,0 +1,183 @@
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSource serves headers of a chain starting at height 0.
+type testSource []wire.BlockHeader
+
+func (s testSource) GetBTCBlockHeaderByHeight(height int64) (*wire.BlockHeader, error) {
+	if height < 0 || height >= int64(len(s)) {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	return &s[height], nil
+}
+
+// regtestChain returns count headers starting from a genesis one day in the past.
+func regtestChain(t *testing.T, count int) []wire.BlockHeader {
+	t.Helper()
+	genesis := wire.BlockHeader{Timestamp: time.Unix(time.Now().Add(-24*time.Hour).Unix(), 0)}
+	return append([]wire.BlockHeader{genesis}, MineTestChain(t, genesis, count-1)...)
+}
+
+func assertRuleError(t *testing.T, err error, code blockchain.ErrorCode) {
+	t.Helper()
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+	var ruleErr blockchain.RuleError
+	if assert.ErrorAs(t, err, &ruleErr) {
+		assert.Equal(t, code, ruleErr.ErrorCode)
+	}
+}
+
+func TestValidateHeadersRegtest(t *testing.T) {
+	chain := regtestChain(t, 30)
+	source := testSource(chain[:15])
+	powLimitBits := chaincfg.RegressionNetParams.PowLimitBits
+
+	tests := []struct {
+		name    string
+		headers func() []wire.BlockHeader
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name:    "valid chain",
+			headers: func() []wire.BlockHeader { return chain[15:] },
+		},
+		{
+			name: "broken linkage inside chunk",
+			headers: func() []wire.BlockHeader {
+				hs := append([]wire.BlockHeader{}, chain[15:20]...)
+				hs[3] = MineTestHeader(t, &chain[10], hs[2].Timestamp.Add(time.Minute), powLimitBits)
+				return hs
+			},
+			check: func(t *testing.T, err error) { assert.ErrorIs(t, err, ErrHeaderLinkage) },
+		},
+		{
+			name: "chunk doesn't extend the source chain",
+			headers: func() []wire.BlockHeader {
+				fork := MineTestHeader(t, &chain[13], chain[14].Timestamp.Add(time.Second), powLimitBits)
+				return MineTestChain(t, fork, 3)
+			},
+			check: func(t *testing.T, err error) { assert.ErrorIs(t, err, ErrHeaderLinkage) },
+		},
+		{
+			name: "hash above target",
+			headers: func() []wire.BlockHeader {
+				h := chain[15]
+				target := blockchain.CompactToBig(h.Bits)
+				for {
+					h.Nonce++
+					hash := h.BlockHash()
+					if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+						return []wire.BlockHeader{h}
+					}
+				}
+			},
+			check: func(t *testing.T, err error) { assertRuleError(t, err, blockchain.ErrHighHash) },
+		},
+		{
+			name: "unexpected difficulty (regtest doesn't retarget)",
+			headers: func() []wire.BlockHeader {
+				return []wire.BlockHeader{MineTestHeader(t, &chain[14], chain[15].Timestamp, 0x2007ffff)}
+			},
+			check: func(t *testing.T, err error) { assertRuleError(t, err, blockchain.ErrUnexpectedDifficulty) },
+		},
+		{
+			name: "timestamp not after median time past",
+			headers: func() []wire.BlockHeader {
+				return []wire.BlockHeader{MineTestHeader(t, &chain[14], chain[9].Timestamp, powLimitBits)}
+			},
+			check: func(t *testing.T, err error) { assertRuleError(t, err, blockchain.ErrTimeTooOld) },
+		},
+		{
+			name: "timestamp too far in the future",
+			headers: func() []wire.BlockHeader {
+				ts := time.Unix(time.Now().Add(3*time.Hour).Unix(), 0)
+				return []wire.BlockHeader{MineTestHeader(t, &chain[14], ts, powLimitBits)}
+			},
+			check: func(t *testing.T, err error) { assertRuleError(t, err, blockchain.ErrTimeTooNew) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewHeaderValidator(&chaincfg.RegressionNetParams, source)
+			err := v.ValidateHeaders(15, tt.headers())
+			if tt.check == nil {
+				assert.NoError(t, err)
+				return
+			}
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestValidateHeadersTestnetMinDifficulty(t *testing.T) {
+	// testnet rules with the regtest proof-of-work limit, so headers can be mined in tests
+	params := chaincfg.TestNet3Params
+	params.PowLimit = chaincfg.RegressionNetParams.PowLimit
+	params.PowLimitBits = chaincfg.RegressionNetParams.PowLimitBits
+	const bits = 0x2007ffff
+
+	genesis := wire.BlockHeader{Timestamp: time.Unix(time.Now().Add(-24*time.Hour).Unix(), 0), Bits: bits}
+	chain := []wire.BlockHeader{genesis}
+	for i := 1; i < 15; i++ {
+		prev := chain[i-1]
+		chain = append(chain, MineTestHeader(t, &prev, prev.Timestamp.Add(10*time.Minute), bits))
+	}
+	tip := chain[14]
+	minDiffAfterGap := MineTestHeader(t, &tip, tip.Timestamp.Add(25*time.Minute), params.PowLimitBits)
+
+	tests := []struct {
+		name    string
+		headers []wire.BlockHeader
+		wantErr bool
+	}{
+		{
+			name:    "min difficulty after 20 minutes without a block",
+			headers: []wire.BlockHeader{minDiffAfterGap},
+		},
+		{
+			name:    "min difficulty within 20 minutes",
+			headers: []wire.BlockHeader{MineTestHeader(t, &tip, tip.Timestamp.Add(5*time.Minute), params.PowLimitBits)},
+			wantErr: true,
+		},
+		{
+			name: "back to the last non min difficulty",
+			headers: []wire.BlockHeader{
+				minDiffAfterGap,
+				MineTestHeader(t, &minDiffAfterGap, minDiffAfterGap.Timestamp.Add(time.Minute), bits),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewHeaderValidator(&params, testSource(chain))
+			err := v.ValidateHeaders(15, tt.headers)
+			if tt.wantErr {
+				assertRuleError(t, err, blockchain.ErrUnexpectedDifficulty)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHeadersSourceError(t *testing.T) {
+	chain := regtestChain(t, 20)
+	v := NewHeaderValidator(&chaincfg.RegressionNetParams, testSource(chain[:5]))
+	err := v.ValidateHeaders(15, chain[15:])
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+	assert.ErrorContains(t, err, "can't fetch header at height 14")
+	assert.False(t, errors.Is(err, ErrHeaderLinkage))
+}
@@ -0,0 +1,32 @@
+package btc
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// GetChainParams returns the chain parameters of the given Bitcoin network.
+func GetChainParams(net string) (*chaincfg.Params, error) {
+	switch net {
+	case Mainnet.String():
+		return &chaincfg.MainNetParams, nil
+	case Testnet.String():
+		return &chaincfg.TestNet3Params, nil
+	case Simnet.String():
+		return &chaincfg.SimNetParams, nil
+	case Regtest.String():
+		return &chaincfg.RegressionNetParams, nil
+	case Signet.String():
+		return &chaincfg.SigNetParams, nil
+	}
+	return nil, fmt.Errorf(
+		"invalid BTC network '%s'. Valid networks are: %s, %s, %s, %s, %s",
+		net,
+		Mainnet.String(),
+		Testnet.String(),
+		Simnet.String(),
+		Regtest.String(),
+		Signet.String(),
+	)
+}
@@ -3,7 +3,7 @@ package btc
 type (
 	// SupportedNetwork represents a supported Bitcoin network type (mainnet, testnet, etc.)
 	SupportedNetwork string
-	// SupportedBackend represents a supported Bitcoin backend implementation (btcd, bitcoind)
+	// SupportedBackend represents a supported Bitcoin backend implementation (btcd, bitcoind, polling, esplora, p2p)
 	SupportedBackend string
 )
 
@@ -20,6 +20,12 @@ const (
 const (
 	Btcd     SupportedBackend = "btcd"
 	Bitcoind SupportedBackend = "bitcoind"
+	// Polling is any node exposing the JSON-RPC API, polled for new blocks
+	Polling SupportedBackend = "polling"
+	// Esplora is an Esplora compatible HTTP API, polled for new blocks
+	Esplora SupportedBackend = "esplora"
+	// P2P is any node reachable with the Bitcoin wire protocol, used for headers only
+	P2P SupportedBackend = "p2p"
 )
 
 func (n SupportedNetwork) String() string {
@@ -46,5 +52,8 @@ func GetValidBackends() map[SupportedBackend]bool {
 	return map[SupportedBackend]bool{
 		Bitcoind: true,
 		Btcd:     true,
+		Polling:  true,
+		Esplora:  true,
+		P2P:      true,
 	}
 }
@@ -0,0 +1,45 @@
+package btc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MineTestHeader is a test helper that returns a header extending prev, with the given
+// timestamp and bits and a nonce satisfying the proof-of-work. Use easy targets only
+// (e.g. the regtest limit).
+func MineTestHeader(t *testing.T, prev *wire.BlockHeader, timestamp time.Time, bits uint32) wire.BlockHeader {
+	t.Helper()
+	header := wire.BlockHeader{
+		Version:   4,
+		PrevBlock: prev.BlockHash(),
+		Timestamp: timestamp,
+		Bits:      bits,
+	}
+	target := blockchain.CompactToBig(bits)
+	for {
+		hash := header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			return header
+		}
+		header.Nonce++
+	}
+}
+
+// MineTestChain is a test helper that mines count regtest headers on top of prev,
+// one every 10 minutes.
+func MineTestChain(t *testing.T, prev wire.BlockHeader, count int) []wire.BlockHeader {
+	t.Helper()
+	bits := chaincfg.RegressionNetParams.PowLimitBits
+	headers := make([]wire.BlockHeader, 0, count)
+	for i := 0; i < count; i++ {
+		next := MineTestHeader(t, &prev, prev.Timestamp.Add(10*time.Minute), bits)
+		headers = append(headers, next)
+		prev = next
+	}
+	return headers
+}
@@ -132,6 +132,24 @@ func (cache *BTCCache) RemoveLast() error {
 	return nil
 }
 
+// RemoveAfter removes all blocks with height greater than the given height from the cache
+// and returns them ordered by height.
+func (cache *BTCCache) RemoveAfter(height int64) []*IndexedBlock {
+	cache.Lock()
+	defer cache.Unlock()
+
+	idx := sort.Search(len(cache.blocks), func(i int) bool {
+		return cache.blocks[i].BlockHeight > height
+	})
+	removed := make([]*IndexedBlock, len(cache.blocks)-idx)
+	copy(removed, cache.blocks[idx:])
+	for i := idx; i < len(cache.blocks); i++ {
+		cache.blocks[i] = nil
+	}
+	cache.blocks = cache.blocks[:idx]
+	return removed
+}
+
 // RemoveAll removes all blocks from the cache
 func (cache *BTCCache) RemoveAll() {
 	cache.Lock()
@@ -194,6 +194,27 @@ func TestBTCCache_RemoveLast(t *testing.T) {
 	assert.Equal(t, int64(0), cache.Size())
 }
 
+func TestBTCCache_RemoveAfter(t *testing.T) {
+	cache, _ := NewBTCCache(5)
+	blocks := CreateTestIndexedBlocks(t, 5, 100) // 100, 101, 102, 103, 104
+	cache.Init(blocks)
+
+	// nothing above the tip
+	removed := cache.RemoveAfter(104)
+	assert.Empty(t, removed)
+	assert.Equal(t, int64(5), cache.Size())
+
+	removed = cache.RemoveAfter(102)
+	assert.Equal(t, blocks[3:], removed)
+	assert.Equal(t, int64(3), cache.Size())
+	assert.Equal(t, int64(102), cache.Last().BlockHeight)
+
+	// everything
+	removed = cache.RemoveAfter(50)
+	assert.Equal(t, blocks[:3], removed)
+	assert.True(t, cache.IsEmpty())
+}
+
 func TestBTCCache_RemoveAll(t *testing.T) {
 	cache, _ := NewBTCCache(5)
 
@@ -9,4 +9,8 @@ var (
 	errCacheIncorrectMaxEntries = errors.New("incorrect max entries")
 	errBlockEntriesExceeded     = errors.New("number of blocks is more than maxEntries")
 	errUnorderedBlocks          = errors.New("blocks are not sorted by height")
+
+	// ErrTxNotInBlock is returned when building the merkle proof of a transaction not
+	// included in the block.
+	ErrTxNotInBlock = errors.New("transaction not in block")
 )
@@ -0,0 +1,79 @@
+// revive:disable:var-naming
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// MerkleProof proves the inclusion of a transaction in a block: the transaction index and
+// the merkle branch from the transaction to the block merkle root.
+type MerkleProof struct {
+	TxID    chainhash.Hash
+	TxIndex uint32
+	// Branch lists the sibling hashes of each level of the merkle tree, from the
+	// transactions level up to the level below the root.
+	Branch []chainhash.Hash
+}
+
+// MerkleProof builds the merkle proof of the transaction txID. The block must be a full
+// block, with all its transactions.
+func (indexedBlock *IndexedBlock) MerkleProof(txID chainhash.Hash) (*MerkleProof, error) {
+	txs := indexedBlock.MsgBlock.Transactions
+	level := make([]chainhash.Hash, len(txs))
+	index := -1
+	for i, tx := range txs {
+		level[i] = tx.TxHash()
+		if level[i] == txID {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: tx %s, block %s", ErrTxNotInBlock, txID, indexedBlock.BlockHash())
+	}
+
+	proof := &MerkleProof{
+		TxID: txID,
+		// #nosec G115 -- a block has less than 2^32 transactions
+		TxIndex: uint32(index),
+	}
+	for pos := index; len(level) > 1; pos /= 2 {
+		// a level with an odd number of nodes duplicates the last one
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		proof.Branch = append(proof.Branch, level[pos^1])
+
+		next := make([]chainhash.Hash, len(level)/2)
+		for i := range next {
+			next[i] = hashMerkleBranches(&level[2*i], &level[2*i+1])
+		}
+		level = next
+	}
+	return proof, nil
+}
+
+// MerkleRoot computes the merkle root committed by the proof. The proof is valid when it
+// matches the merkle root of the block header.
+func (p *MerkleProof) MerkleRoot() chainhash.Hash {
+	root := p.TxID
+	index := p.TxIndex
+	for i := range p.Branch {
+		if index&1 == 0 {
+			root = hashMerkleBranches(&root, &p.Branch[i])
+		} else {
+			root = hashMerkleBranches(&p.Branch[i], &root)
+		}
+		index >>= 1
+	}
+	return root
+}
+
+func hashMerkleBranches(left, right *chainhash.Hash) chainhash.Hash {
+	var b [chainhash.HashSize * 2]byte
+	copy(b[:chainhash.HashSize], left[:])
+	copy(b[chainhash.HashSize:], right[:])
+	return chainhash.DoubleHashH(b[:])
+}
@@ -0,0 +1,65 @@
+// revive:disable:var-naming
+
+package types
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlock(numTxs int) *IndexedBlock {
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{})
+	for i := 0; i < numTxs; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		// #nosec G115
+		tx.LockTime = uint32(i)
+		_ = msgBlock.AddTransaction(tx)
+	}
+	msgBlock.Header.MerkleRoot = blockchain.CalcMerkleRoot(GetWrappedTxs(msgBlock), false)
+	return NewIndexedBlock(100, msgBlock)
+}
+
+func TestMerkleProof(t *testing.T) {
+	for _, numTxs := range []int{1, 2, 3, 5, 8, 11} {
+		block := testBlock(numTxs)
+		for i, tx := range block.MsgBlock.Transactions {
+			proof, err := block.MerkleProof(tx.TxHash())
+			require.NoError(t, err)
+			// #nosec G115
+			assert.Equal(t, uint32(i), proof.TxIndex)
+			assert.Equal(t, block.MsgBlock.Header.MerkleRoot, proof.MerkleRoot(),
+				"txs %d, index %d", numTxs, i)
+		}
+	}
+
+	t.Run("branch size", func(t *testing.T) {
+		proof, err := testBlock(1).MerkleProof(testBlock(1).MsgBlock.Transactions[0].TxHash())
+		require.NoError(t, err)
+		assert.Empty(t, proof.Branch)
+
+		block := testBlock(5)
+		proof, err = block.MerkleProof(block.MsgBlock.Transactions[4].TxHash())
+		require.NoError(t, err)
+		assert.Len(t, proof.Branch, 3)
+		// the last tx of an odd level is paired with itself
+		assert.Equal(t, block.MsgBlock.Transactions[4].TxHash(), proof.Branch[0])
+	})
+
+	t.Run("tx not in block", func(t *testing.T) {
+		_, err := testBlock(3).MerkleProof(chainhash.Hash{1})
+		assert.ErrorIs(t, err, ErrTxNotInBlock)
+	})
+
+	t.Run("wrong index", func(t *testing.T) {
+		block := testBlock(4)
+		proof, err := block.MerkleProof(block.MsgBlock.Transactions[1].TxHash())
+		require.NoError(t, err)
+		proof.TxIndex = 0
+		assert.NotEqual(t, block.MsgBlock.Header.MerkleRoot, proof.MerkleRoot())
+	})
+}
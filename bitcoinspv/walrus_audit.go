@@ -0,0 +1,163 @@
+package bitcoinspv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/rs/zerolog"
+)
+
+// Walrus audit statuses of a block.
+const (
+	WalrusAuditOK       = "ok"
+	WalrusAuditMissing  = "missing"
+	WalrusAuditMismatch = "mismatch"
+)
+
+// WalrusAuditEntry is the audit result of the block at a height.
+type WalrusAuditEntry struct {
+	BlockHash string `json:"block_hash"`
+	BlobID    string `json:"blob_id,omitempty"`
+	// Status is ok, missing (no blob recorded or the blob can't be read) or mismatch.
+	Status string `json:"status"`
+	// NewBlobID is the blob ID of the re-uploaded block, when repaired.
+	NewBlobID string `json:"new_blob_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Height    int64  `json:"height"`
+	Repaired  bool   `json:"repaired"`
+}
+
+// WalrusAuditor checks that the blocks recorded in the state store can be read back from
+// Walrus and hold the blocks of the node's best chain.
+type WalrusAuditor struct {
+	walrus    *WalrusHandler
+	db        *store.DB
+	btcClient clients.BTCClient
+	logger    zerolog.Logger
+}
+
+// NewWalrusAuditor creates a Walrus auditor.
+func NewWalrusAuditor(
+	wh *WalrusHandler,
+	db *store.DB,
+	btcClient clients.BTCClient,
+	parentLogger zerolog.Logger,
+) *WalrusAuditor {
+	return &WalrusAuditor{
+		walrus:    wh,
+		db:        db,
+		btcClient: btcClient,
+		logger:    parentLogger.With().Str("module", "bitcoinspv/walrus_audit").Logger(),
+	}
+}
+
+// Audit checks sample random heights in the [from, to] range, or all of them when sample
+// is not positive. With repair, missing and mismatching blocks are uploaded again and their
+// new blobs recorded. Entries are sorted by height.
+func (a *WalrusAuditor) Audit(
+	ctx context.Context,
+	from, to int64,
+	sample int,
+	repair bool,
+) ([]WalrusAuditEntry, error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid height range %d-%d", from, to)
+	}
+
+	heights := sampleHeights(from, to, sample)
+	entries := make([]WalrusAuditEntry, 0, len(heights))
+	for _, height := range heights {
+		if err := ctx.Err(); err != nil {
+			return entries, err
+		}
+		entry, err := a.auditHeight(ctx, height, repair)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (a *WalrusAuditor) auditHeight(ctx context.Context, height int64, repair bool) (WalrusAuditEntry, error) {
+	block, err := a.btcClient.GetBTCBlockByHeight(height)
+	if err != nil {
+		return WalrusAuditEntry{}, fmt.Errorf("failed to get block %d: %w", height, err)
+	}
+	hash := block.BlockHash()
+	entry := WalrusAuditEntry{Height: height, BlockHash: hash.String(), Status: WalrusAuditOK}
+
+	record, err := a.db.GetWalrusBlob(ctx, hash[:])
+	if err != nil {
+		return entry, err
+	}
+	if record == nil {
+		entry.Status = WalrusAuditMissing
+	} else {
+		entry.BlobID = record.BlobID
+		if err := a.walrus.VerifyBlob(record.BlobID, hash); err != nil {
+			entry.Status = WalrusAuditMissing
+			if errors.Is(err, ErrWalrusBlobMismatch) {
+				entry.Status = WalrusAuditMismatch
+			}
+			entry.Error = err.Error()
+		}
+	}
+	if entry.Status == WalrusAuditOK {
+		return entry, nil
+	}
+	a.logger.Warn().Int64("height", height).Str("hash", entry.BlockHash).Str("status", entry.Status).
+		Str("blob_id", entry.BlobID).Msg("Walrus audit found a block that can't be read back")
+	if !repair {
+		return entry, nil
+	}
+
+	var raw bytes.Buffer
+	if err := block.MsgBlock.Serialize(&raw); err != nil {
+		return entry, fmt.Errorf("failed to serialize block %d: %w", height, err)
+	}
+	blob, err := a.walrus.StoreVerifiedBlock(raw.Bytes(), height, hash)
+	if err != nil {
+		entry.Error = fmt.Sprintf("repair failed: %v", err)
+		return entry, nil
+	}
+	if err := recordWalrusBlob(ctx, a.db, height, hash, blob); err != nil {
+		return entry, err
+	}
+	entry.Repaired = true
+	entry.NewBlobID = blob.BlobID
+	return entry, nil
+}
+
+// sampleHeights returns up to sample distinct random heights of the [from, to] range in
+// ascending order, or the whole range when sample is not positive or covers it.
+func sampleHeights(from, to int64, sample int) []int64 {
+	n := to - from + 1
+	if sample <= 0 || int64(sample) >= n {
+		heights := make([]int64, 0, n)
+		for h := from; h <= to; h++ {
+			heights = append(heights, h)
+		}
+		return heights
+	}
+
+	picked := make(map[int64]struct{}, sample)
+	heights := make([]int64, 0, sample)
+	for len(heights) < sample {
+		// NOTE: the sample doesn't need secure random generation
+		h := from + rand.Int63n(n) //nolint:gosec
+		if _, ok := picked[h]; ok {
+			continue
+		}
+		picked[h] = struct{}{}
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
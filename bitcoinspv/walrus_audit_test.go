@@ -0,0 +1,192 @@
+package bitcoinspv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	walrus "github.com/namihq/walrus-go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWalrus serves the publisher store and aggregator read endpoints from memory. The
+// blobs are stored from startEpoch to endEpoch.
+type fakeWalrus struct {
+	blobs      map[string][]byte
+	mu         sync.Mutex
+	startEpoch int
+	endEpoch   int
+}
+
+func newFakeWalrus(t *testing.T) (*fakeWalrus, *WalrusHandler) {
+	t.Helper()
+	f := &fakeWalrus{blobs: map[string][]byte{}, endEpoch: 10}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/blobs", func(w http.ResponseWriter, req *http.Request) {
+		data, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		f.mu.Lock()
+		id := fmt.Sprintf("blob-%d", len(f.blobs))
+		f.blobs[id] = data
+		storage := walrus.StorageInfo{StartEpoch: f.startEpoch, EndEpoch: f.endEpoch}
+		f.mu.Unlock()
+		var resp walrus.StoreResponse
+		resp.NewlyCreated = &struct {
+			BlobObject  walrus.BlobObject `json:"blobObject"`
+			EncodedSize int               `json:"encodedSize"`
+			Cost        int               `json:"cost"`
+		}{BlobObject: walrus.BlobObject{BlobID: id, Storage: storage}, Cost: 5}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+	mux.HandleFunc("GET /v1/blobs/{id}", func(w http.ResponseWriter, req *http.Request) {
+		f.mu.Lock()
+		data, ok := f.blobs[req.PathValue("id")]
+		f.mu.Unlock()
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	wh := &WalrusHandler{
+		client: walrus.NewClient(
+			walrus.WithPublisherURLs([]string{srv.URL}),
+			walrus.WithAggregatorURLs([]string{srv.URL}),
+			walrus.WithRetryConfig(0, 0),
+		),
+		logger: zerolog.Nop(),
+		config: &config.RelayerConfig{WalrusStorageEpochs: 1},
+	}
+	return f, wh
+}
+
+func (f *fakeWalrus) put(id string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[id] = data
+}
+
+func (f *fakeWalrus) setEpochs(start, end int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.startEpoch, f.endEpoch = start, end
+}
+
+func rawBlock(t *testing.T, b *types.IndexedBlock) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, b.MsgBlock.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func TestVerifyBlob(t *testing.T) {
+	f, wh := newFakeWalrus(t)
+	blocks := types.CreateTestIndexedBlocks(t, 2, 100)
+	hash := blocks[0].BlockHash()
+
+	blob, err := wh.StoreVerifiedBlock(rawBlock(t, blocks[0]), 100, hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), blob.Cost)
+	assert.Equal(t, int64(10), blob.EndEpoch)
+	assert.NoError(t, wh.VerifyBlob(blob.BlobID, hash))
+
+	f.put("other", rawBlock(t, blocks[1]))
+	assert.ErrorIs(t, wh.VerifyBlob("other", hash), ErrWalrusBlobMismatch)
+	f.put("garbage", []byte("not a block"))
+	assert.ErrorIs(t, wh.VerifyBlob("garbage", hash), ErrWalrusBlobMismatch)
+
+	err = wh.VerifyBlob("unknown", hash)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrWalrusBlobMismatch)
+}
+
+func TestWalrusAudit(t *testing.T) {
+	ctx := context.Background()
+	blocks := types.CreateTestIndexedBlocks(t, 3, 100)
+
+	setup := func(t *testing.T) *WalrusAuditor {
+		f, wh := newFakeWalrus(t)
+		r, btcClient, _ := setupTest(t)
+		r.SetStateStore(storetest.InitTestDB(ctx, t))
+		for _, b := range blocks {
+			btcClient.On("GetBTCBlockByHeight", b.BlockHeight).Return(b, nil).Maybe()
+		}
+		// 100 is stored, 101 holds the block 100 and 102 is not recorded
+		f.put("blob-a", rawBlock(t, blocks[0]))
+		r.saveWalrusBlob(ctx, 100, blocks[0].BlockHash(), &StoredBlob{BlobID: "blob-a"})
+		r.saveWalrusBlob(ctx, 101, blocks[1].BlockHash(), &StoredBlob{BlobID: "blob-a"})
+		return NewWalrusAuditor(wh, r.stateStore, btcClient, zerolog.Nop())
+	}
+
+	t.Run("report", func(t *testing.T) {
+		a := setup(t)
+		entries, err := a.Audit(ctx, 100, 102, 0, false)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, WalrusAuditOK, entries[0].Status)
+		assert.Equal(t, "blob-a", entries[0].BlobID)
+		assert.Equal(t, WalrusAuditMismatch, entries[1].Status)
+		assert.NotEmpty(t, entries[1].Error)
+		assert.Equal(t, WalrusAuditMissing, entries[2].Status)
+		for _, e := range entries {
+			assert.False(t, e.Repaired)
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		a := setup(t)
+		entries, err := a.Audit(ctx, 100, 102, 0, true)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.False(t, entries[0].Repaired)
+		for _, e := range entries[1:] {
+			assert.True(t, e.Repaired)
+			require.NotEmpty(t, e.NewBlobID)
+			hash, err := chainhash.NewHashFromStr(e.BlockHash)
+			require.NoError(t, err)
+			record, err := a.db.GetWalrusBlob(ctx, hash[:])
+			require.NoError(t, err)
+			assert.Equal(t, e.NewBlobID, record.BlobID)
+		}
+
+		entries, err = a.Audit(ctx, 100, 102, 0, false)
+		require.NoError(t, err)
+		for _, e := range entries {
+			assert.Equal(t, WalrusAuditOK, e.Status)
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		a := setup(t)
+		_, err := a.Audit(ctx, 102, 100, 0, false)
+		assert.Error(t, err)
+	})
+}
+
+func TestSampleHeights(t *testing.T) {
+	assert.Equal(t, []int64{5, 6, 7}, sampleHeights(5, 7, 0))
+	assert.Equal(t, []int64{5, 6, 7}, sampleHeights(5, 7, 10))
+
+	heights := sampleHeights(0, 1000, 20)
+	require.Len(t, heights, 20)
+	for i := 1; i < len(heights); i++ {
+		assert.Less(t, heights[i-1], heights[i])
+	}
+	assert.GreaterOrEqual(t, heights[0], int64(0))
+	assert.LessOrEqual(t, heights[19], int64(1000))
+}
@@ -0,0 +1,136 @@
+package bitcoinspv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/rs/zerolog"
+)
+
+// WalrusBackfillResult reports a Walrus backfill.
+type WalrusBackfillResult struct {
+	// Failed lists the heights of the blocks that couldn't be stored.
+	Failed   []int64 `json:"failed"`
+	From     int64   `json:"from"`
+	To       int64   `json:"to"`
+	Archived int     `json:"archived"`
+	Skipped  int     `json:"skipped"`
+}
+
+// WalrusBackfiller stores in Walrus the blocks of a height range that were not stored while
+// the relayer followed the chain.
+type WalrusBackfiller struct {
+	walrus    *WalrusHandler
+	db        *store.DB
+	btcClient clients.BTCClient
+	workers   int
+	logger    zerolog.Logger
+}
+
+// NewWalrusBackfiller creates a Walrus backfiller fetching and storing the blocks with the
+// given number of workers.
+func NewWalrusBackfiller(
+	wh *WalrusHandler,
+	db *store.DB,
+	btcClient clients.BTCClient,
+	workers int,
+	parentLogger zerolog.Logger,
+) *WalrusBackfiller {
+	return &WalrusBackfiller{
+		walrus:    wh,
+		db:        db,
+		btcClient: btcClient,
+		workers:   max(workers, 1),
+		logger:    parentLogger.With().Str("module", "bitcoinspv/walrus_backfill").Logger(),
+	}
+}
+
+// Backfill fetches the blocks of the [from, to] range from the node and stores them in
+// Walrus, recording their blobs. Heights with a blob in the index are skipped. A block that
+// can't be fetched or stored is reported in the result and doesn't stop the backfill.
+func (b *WalrusBackfiller) Backfill(ctx context.Context, from, to int64) (*WalrusBackfillResult, error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid height range %d-%d", from, to)
+	}
+	stored, err := b.db.ListWalrusBlobs(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	storedHeights := make(map[int64]struct{}, len(stored))
+	for _, blob := range stored {
+		storedHeights[blob.Height] = struct{}{}
+	}
+
+	res := &WalrusBackfillResult{From: from, To: to, Skipped: len(storedHeights), Failed: []int64{}}
+	b.logger.Info().Int64("from", from).Int64("to", to).Int("skipped", res.Skipped).
+		Msg("Starting Walrus backfill")
+
+	heights := make(chan int64)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				err := b.archive(ctx, height)
+				mu.Lock()
+				if err != nil {
+					b.logger.Warn().Err(err).Int64("height", height).Msg("Walrus backfill failed")
+					res.Failed = append(res.Failed, height)
+				} else {
+					res.Archived++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for height := from; height <= to; height++ {
+		if _, ok := storedHeights[height]; ok {
+			continue
+		}
+		select {
+		case heights <- height:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(heights)
+	wg.Wait()
+
+	sort.Slice(res.Failed, func(i, j int) bool { return res.Failed[i] < res.Failed[j] })
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	b.logger.Info().Int("archived", res.Archived).Int("failed", len(res.Failed)).
+		Msg("Walrus backfill completed")
+	return res, nil
+}
+
+func (b *WalrusBackfiller) archive(ctx context.Context, height int64) error {
+	block, err := b.btcClient.GetBTCBlockByHeight(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", height, err)
+	}
+	blob, err := b.walrus.ArchiveBlock(block.MsgBlock, height)
+	if err != nil {
+		return err
+	}
+	hash := block.BlockHash()
+	if err := recordWalrusBlob(ctx, b.db, height, hash, blob); err != nil {
+		return err
+	}
+	return b.db.AdvanceCheckpoint(ctx, store.Checkpoint{
+		Name:      store.CheckpointWalrus,
+		Height:    height,
+		BlockHash: hash[:],
+		Timestamp: time.Now().Unix(),
+	})
+}
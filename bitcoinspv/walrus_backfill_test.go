@@ -0,0 +1,86 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalrusBackfill(t *testing.T) {
+	ctx := context.Background()
+	blocks := types.CreateTestIndexedBlocks(t, 5, 100)
+
+	_, wh := newFakeWalrus(t)
+	_, btcClient, _ := setupTest(t)
+	db := storetest.InitTestDB(ctx, t)
+	for _, b := range blocks {
+		if b.BlockHeight == 103 {
+			btcClient.On("GetBTCBlockByHeight", b.BlockHeight).Return(nil, errors.New("node unavailable"))
+			continue
+		}
+		btcClient.On("GetBTCBlockByHeight", b.BlockHeight).Return(b, nil).Maybe()
+	}
+	// 101 is already stored
+	require.NoError(t, db.UpsertWalrusBlob(ctx, walrusBlobRecord(101, blocks[1].BlockHash(), &StoredBlob{BlobID: "blob-a"})))
+
+	res, err := NewWalrusBackfiller(wh, db, btcClient, 2, zerolog.Nop()).Backfill(ctx, 100, 104)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Archived)
+	assert.Equal(t, 1, res.Skipped)
+	assert.Equal(t, []int64{103}, res.Failed)
+
+	for _, b := range []*types.IndexedBlock{blocks[0], blocks[2], blocks[4]} {
+		hash := b.BlockHash()
+		blob, err := db.GetWalrusBlob(ctx, hash[:])
+		require.NoError(t, err)
+		require.NotNil(t, blob)
+		assert.NoError(t, wh.VerifyBlob(blob.BlobID, hash))
+	}
+	hash101 := blocks[1].BlockHash()
+	blob, err := db.GetWalrusBlob(ctx, hash101[:])
+	require.NoError(t, err)
+	assert.Equal(t, "blob-a", blob.BlobID)
+
+	cp, err := db.GetCheckpoint(ctx, store.CheckpointWalrus)
+	require.NoError(t, err)
+	assert.Equal(t, int64(104), cp.Height)
+
+	_, err = NewWalrusBackfiller(wh, db, btcClient, 2, zerolog.Nop()).Backfill(ctx, 104, 100)
+	assert.Error(t, err)
+}
+
+func TestWalrusCatchup(t *testing.T) {
+	ctx := context.Background()
+	blocks := types.CreateTestIndexedBlocks(t, 3, 100)
+
+	_, wh := newFakeWalrus(t)
+	r, btcClient, _ := setupTest(t)
+	r.walrusHandler = wh
+	r.SetStateStore(storetest.InitTestDB(ctx, t))
+	r.saveCheckpoint(ctx, store.CheckpointWalrus, 100, blocks[0].BlockHash())
+	btcClient.On("GetBTCTipBlock").Return(nil, int64(102), nil)
+	for _, b := range blocks[1:] {
+		btcClient.On("GetBTCBlockByHeight", b.BlockHeight).Return(b, nil)
+	}
+
+	r.startWalrusCatchup()
+	// the checkpoint is advanced after the blob is recorded
+	require.Eventually(t, func() bool {
+		cp, err := r.stateStore.GetCheckpoint(ctx, store.CheckpointWalrus)
+		return err == nil && cp.Height == 102
+	}, time.Second, time.Millisecond)
+	r.Stop()
+	r.WaitForShutdown()
+
+	blobs, err := r.stateStore.ListWalrusBlobs(ctx, 100, 102)
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2)
+}
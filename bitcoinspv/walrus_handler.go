@@ -1,14 +1,25 @@
 package bitcoinspv
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
 	walrus "github.com/namihq/walrus-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 )
 
+// ErrWalrusBlobMismatch is returned when a Walrus blob doesn't hold the expected block.
+var ErrWalrusBlobMismatch = errors.New("walrus blob doesn't match the block")
+
 // WalrusHandler wraps the Walrus client
 type WalrusHandler struct {
 	client *walrus.Client
@@ -46,12 +57,49 @@ func NewWalrusHandler(cfg *config.RelayerConfig, parentLogger zerolog.Logger) (*
 	}, nil
 }
 
+// StoredBlob describes the Walrus blob storing a block.
+type StoredBlob struct {
+	BlobID string
+	// Cost is the storage cost in FROST, zero when the blob was already certified.
+	Cost int64
+	// EndEpoch is the Walrus epoch at which the blob expires.
+	EndEpoch int64
+	// StartEpoch is the Walrus epoch at which the storage was bought, zero when the blob was
+	// already certified.
+	StartEpoch int64
+}
+
+// WalrusBlobInfo describes a block stored in Walrus, as reported by the CLI and the API.
+// The raw block is read from an aggregator with the blob ID.
+type WalrusBlobInfo struct {
+	Height    int64     `json:"height"`
+	BlockHash string    `json:"block_hash"`
+	BlobID    string    `json:"blob_id"`
+	Cost      int64     `json:"cost"`
+	EndEpoch  int64     `json:"end_epoch"`
+	StoredAt  time.Time `json:"stored_at"`
+}
+
+// NewWalrusBlobInfo converts a Walrus blob record of the state store.
+func NewWalrusBlobInfo(b *store.WalrusBlob) WalrusBlobInfo {
+	var hash chainhash.Hash
+	copy(hash[:], b.BlockHash)
+	return WalrusBlobInfo{
+		Height:    b.Height,
+		BlockHash: hash.String(),
+		BlobID:    b.BlobID,
+		Cost:      b.Cost,
+		EndEpoch:  b.EndEpoch,
+		StoredAt:  time.Unix(b.Timestamp, 0).UTC(),
+	}
+}
+
 // StoreBlock attempts to store the raw block data in Walrus.
 func (wh *WalrusHandler) StoreBlock(
 	rawBlockData []byte,
 	blockHeight int64,
 	blockHashStr string,
-) (*string, error) {
+) (*StoredBlob, error) {
 	rawBlockHex := hex.EncodeToString(rawBlockData)
 	// only for debug (block can be very long)
 	wh.logger.Debug().Msgf("Storing block: {\n heigh:%d,\n hash:%s,\n raw_block:%s\n} in Walrus...",
@@ -66,22 +114,96 @@ func (wh *WalrusHandler) StoreBlock(
 		return nil, err
 	}
 
-	var blobID string
+	var blob StoredBlob
 	switch {
 	case resp.NewlyCreated != nil:
-		blobID = resp.NewlyCreated.BlobObject.BlobID
+		blob = StoredBlob{
+			BlobID:     resp.NewlyCreated.BlobObject.BlobID,
+			Cost:       int64(resp.NewlyCreated.Cost),
+			EndEpoch:   int64(resp.NewlyCreated.BlobObject.Storage.EndEpoch),
+			StartEpoch: int64(resp.NewlyCreated.BlobObject.Storage.StartEpoch),
+		}
 		wh.logger.Info().Msgf(
 			"Block %d (%s) newly stored in Walrus. Blob ID: %s, Cost: %d",
-			blockHeight, blockHashStr, blobID, resp.NewlyCreated.Cost,
+			blockHeight, blockHashStr, blob.BlobID, blob.Cost,
 		)
 	case resp.AlreadyCertified != nil:
-		blobID = resp.AlreadyCertified.BlobID
+		blob = StoredBlob{
+			BlobID:   resp.AlreadyCertified.BlobID,
+			EndEpoch: int64(resp.AlreadyCertified.EndEpoch),
+		}
 		wh.logger.Info().Msgf(
 			"Block %d (%s) data already stored in Walrus. Blob ID: %s, End Epoch: %d",
-			blockHeight, blockHashStr, blobID, resp.AlreadyCertified.EndEpoch,
+			blockHeight, blockHashStr, blob.BlobID, blob.EndEpoch,
 		)
 	default:
 		return nil, fmt.Errorf("unexpected Walrus store response for block %d (%s)", blockHeight, blockHashStr)
 	}
-	return &blobID, nil
+	return &blob, nil
+}
+
+// VerifyBlob reads the blob back from the Walrus aggregators and checks it holds the block
+// with the given hash. Returns ErrWalrusBlobMismatch when the blob holds other data.
+func (wh *WalrusHandler) VerifyBlob(blobID string, blockHash chainhash.Hash) error {
+	data, err := wh.client.Read(blobID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", blobID, err)
+	}
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("%w: blob %s is not a block: %v", ErrWalrusBlobMismatch, blobID, err)
+	}
+	if hash := block.BlockHash(); hash != blockHash {
+		return fmt.Errorf("%w: blob %s holds block %s, expected %s", ErrWalrusBlobMismatch, blobID, hash, blockHash)
+	}
+	return nil
+}
+
+// ArchiveBlock stores a full block in Walrus. With walrus-verify-uploads, the blob is read
+// back to check it holds the block.
+func (wh *WalrusHandler) ArchiveBlock(msgBlock *wire.MsgBlock, blockHeight int64) (*StoredBlob, error) {
+	blockHash := msgBlock.BlockHash()
+	var blockBuffer bytes.Buffer
+	if err := msgBlock.Serialize(&blockBuffer); err != nil {
+		return nil, fmt.Errorf("failed to serialize block %d (%s): %w", blockHeight, blockHash, err)
+	}
+	rawBlockData := blockBuffer.Bytes()
+
+	timer := prometheus.NewTimer(metrics.WalrusUploadDuration)
+	var blob *StoredBlob
+	var err error
+	if wh.config.WalrusVerifyUploads {
+		blob, err = wh.StoreVerifiedBlock(rawBlockData, blockHeight, blockHash)
+	} else {
+		blob, err = wh.StoreBlock(rawBlockData, blockHeight, blockHash.String())
+	}
+	timer.ObserveDuration()
+	if err != nil {
+		return nil, fmt.Errorf("walrus store failed for block %d (%s): %w", blockHeight, blockHash, err)
+	}
+	return blob, nil
+}
+
+// StoreVerifiedBlock stores the raw block in Walrus and reads it back to check it can be
+// retrieved. When the check fails, the block is stored once more.
+func (wh *WalrusHandler) StoreVerifiedBlock(
+	rawBlockData []byte,
+	blockHeight int64,
+	blockHash chainhash.Hash,
+) (*StoredBlob, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var blob *StoredBlob
+		blob, err = wh.StoreBlock(rawBlockData, blockHeight, blockHash.String())
+		if err != nil {
+			return nil, err
+		}
+		if err = wh.VerifyBlob(blob.BlobID, blockHash); err == nil {
+			return blob, nil
+		}
+		metrics.WalrusVerifyFailures.Inc()
+		wh.logger.Warn().Err(err).Int64("height", blockHeight).Str("blob_id", blob.BlobID).
+			Msg("Walrus blob verification failed")
+	}
+	return nil, err
 }
@@ -0,0 +1,271 @@
+package bitcoinspv
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/rs/zerolog"
+)
+
+// walrusQueueMemBlocks is the number of queued blocks kept in memory. The other queued blocks
+// are fetched again from the node when uploaded.
+const walrusQueueMemBlocks = 16
+
+// walrusUpload is a block queued for upload to Walrus.
+type walrusUpload struct {
+	hash   chainhash.Hash
+	height int64
+	// block is nil when it must be fetched from the node.
+	block    *wire.MsgBlock
+	attempts int64
+	next     time.Time
+	inFlight bool
+}
+
+// walrusQueue uploads blocks to Walrus with a pool of background workers, so the relay of
+// headers doesn't wait for Walrus. Failed uploads are retried with exponential backoff.
+// The queue is persisted in the state store, when set, so queued blocks survive restarts.
+type walrusQueue struct {
+	workers   int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	db        *store.DB
+	// fetch returns a block from the node.
+	fetch func(hash *chainhash.Hash) (*wire.MsgBlock, error)
+	// upload stores a block in Walrus.
+	upload func(block *wire.MsgBlock, height int64) error
+	logger zerolog.Logger
+
+	mu        sync.Mutex
+	pending   map[chainhash.Hash]*walrusUpload
+	memBlocks int
+	wake      chan struct{}
+	jobs      chan *walrusUpload
+}
+
+func newWalrusQueue(
+	workers int,
+	baseDelay, maxDelay time.Duration,
+	db *store.DB,
+	fetch func(hash *chainhash.Hash) (*wire.MsgBlock, error),
+	upload func(block *wire.MsgBlock, height int64) error,
+	parentLogger zerolog.Logger,
+) *walrusQueue {
+	if baseDelay <= 0 {
+		baseDelay = time.Second
+	}
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+	return &walrusQueue{
+		workers:   workers,
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		db:        db,
+		fetch:     fetch,
+		upload:    upload,
+		logger:    parentLogger.With().Str("module", "bitcoinspv/walrus_queue").Logger(),
+		pending:   map[chainhash.Hash]*walrusUpload{},
+		wake:      make(chan struct{}, 1),
+		jobs:      make(chan *walrusUpload),
+	}
+}
+
+// load queues the uploads persisted in the state store by a previous run.
+func (q *walrusQueue) load(ctx context.Context) error {
+	if q.db == nil {
+		return nil
+	}
+	uploads, err := q.db.ListWalrusUploads(ctx)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, u := range uploads {
+		var hash chainhash.Hash
+		copy(hash[:], u.BlockHash)
+		if _, ok := q.pending[hash]; ok {
+			continue
+		}
+		q.pending[hash] = &walrusUpload{hash: hash, height: u.Height, attempts: u.Attempts}
+	}
+	metrics.WalrusUploadQueueSize.Set(float64(len(q.pending)))
+	if len(uploads) > 0 {
+		q.logger.Info().Int("blocks", len(uploads)).Msg("Resuming queued Walrus uploads")
+	}
+	return nil
+}
+
+// enqueue queues the upload of a block. Queuing a block twice is a no-op.
+func (q *walrusQueue) enqueue(ctx context.Context, block *wire.MsgBlock, height int64) {
+	hash := block.BlockHash()
+	q.mu.Lock()
+	_, queued := q.pending[hash]
+	q.mu.Unlock()
+	if queued {
+		return
+	}
+	// persisted first, so a completed upload can't leave a stale entry
+	if q.db != nil {
+		if err := q.db.InsertWalrusUpload(ctx, hash[:], height); err != nil {
+			q.logger.Warn().Err(err).Int64("height", height).Msg("Failed to persist queued Walrus upload")
+		}
+	}
+
+	q.mu.Lock()
+	if _, ok := q.pending[hash]; !ok {
+		u := &walrusUpload{hash: hash, height: height, next: time.Now()}
+		if q.memBlocks < walrusQueueMemBlocks {
+			u.block = block
+			q.memBlocks++
+		}
+		q.pending[hash] = u
+		metrics.WalrusUploadQueueSize.Set(float64(len(q.pending)))
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start launches the dispatcher and the workers, running until quit is closed.
+func (q *walrusQueue) start(quit <-chan struct{}, wg *sync.WaitGroup) {
+	wg.Add(1 + q.workers)
+	go func() {
+		defer wg.Done()
+		q.dispatch(quit)
+	}()
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-quit:
+					return
+				case u := <-q.jobs:
+					q.process(u)
+				}
+			}
+		}()
+	}
+}
+
+// dispatch hands the due uploads, lowest heights first, to the workers.
+func (q *walrusQueue) dispatch(quit <-chan struct{}) {
+	for {
+		u, wait := q.nextDue()
+		if u != nil {
+			select {
+			case q.jobs <- u:
+			case <-quit:
+				q.mu.Lock()
+				u.inFlight = false
+				q.mu.Unlock()
+				return
+			}
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-quit:
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// nextDue returns the due upload with the lowest height, marked in flight, or the time to
+// wait for the next upload to be due.
+func (q *walrusQueue) nextDue() (*walrusUpload, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	wait := q.maxDelay
+	due := make([]*walrusUpload, 0, len(q.pending))
+	for _, u := range q.pending {
+		if u.inFlight {
+			continue
+		}
+		if d := u.next.Sub(now); d > 0 {
+			wait = min(wait, d)
+			continue
+		}
+		due = append(due, u)
+	}
+	if len(due) == 0 {
+		return nil, wait
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].height < due[j].height })
+	due[0].inFlight = true
+	return due[0], 0
+}
+
+// process uploads a block, scheduling a retry when it fails.
+func (q *walrusQueue) process(u *walrusUpload) {
+	ctx := context.Background()
+	block := u.block
+	var err error
+	if block == nil {
+		block, err = q.fetch(&u.hash)
+	}
+	if err == nil {
+		err = q.upload(block, u.height)
+	}
+
+	q.mu.Lock()
+	if u.block != nil {
+		// dropped after the first attempt, the retries fetch the block again
+		u.block = nil
+		q.memBlocks--
+	}
+	if err == nil {
+		delete(q.pending, u.hash)
+		metrics.WalrusUploadQueueSize.Set(float64(len(q.pending)))
+		q.mu.Unlock()
+		if q.db != nil {
+			if err := q.db.DeleteWalrusUpload(ctx, u.hash[:]); err != nil {
+				q.logger.Warn().Err(err).Int64("height", u.height).Msg("Failed to remove stored Walrus upload")
+			}
+		}
+		return
+	}
+	u.attempts++
+	delay := q.backoff(u.attempts)
+	u.next = time.Now().Add(delay)
+	u.inFlight = false
+	attempts := u.attempts
+	q.mu.Unlock()
+
+	metrics.WalrusUploadRetries.Inc()
+	q.logger.Warn().Err(err).Int64("height", u.height).Str("hash", u.hash.String()).
+		Int64("attempts", attempts).Dur("retry_in", delay).Msg("Walrus upload failed")
+	if q.db != nil {
+		if dbErr := q.db.UpdateWalrusUploadAttempt(ctx, u.hash[:], attempts, err.Error()); dbErr != nil {
+			q.logger.Warn().Err(dbErr).Int64("height", u.height).Msg("Failed to persist Walrus upload attempt")
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt, doubled at each failed attempt.
+func (q *walrusQueue) backoff(attempts int64) time.Duration {
+	delay := q.baseDelay
+	for i := int64(1); i < attempts && delay < q.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, q.maxDelay)
+}
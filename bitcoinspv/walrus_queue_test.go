@@ -0,0 +1,154 @@
+package bitcoinspv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUploads records the blocks uploaded by a queue, failing the first attempts of the
+// blocks of failHeights.
+type fakeUploads struct {
+	mu          sync.Mutex
+	blocks      map[int64]*types.IndexedBlock
+	failHeights map[int64]int
+	uploaded    []int64
+	fetched     []int64
+}
+
+func (f *fakeUploads) fetch(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for h, b := range f.blocks {
+		if b.BlockHash() == *hash {
+			f.fetched = append(f.fetched, h)
+			return b.MsgBlock, nil
+		}
+	}
+	return nil, errors.New("unknown block")
+}
+
+func (f *fakeUploads) upload(block *wire.MsgBlock, height int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.blocks[height].BlockHash() != block.BlockHash() {
+		return errors.New("wrong block")
+	}
+	if f.failHeights[height] > 0 {
+		f.failHeights[height]--
+		return errors.New("publisher unavailable")
+	}
+	f.uploaded = append(f.uploaded, height)
+	return nil
+}
+
+func (f *fakeUploads) uploadedHeights() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int64{}, f.uploaded...)
+}
+
+func TestWalrusQueue(t *testing.T) {
+	ctx := context.Background()
+	testBlocks := types.CreateTestIndexedBlocks(t, 3, 100)
+
+	setup := func(t *testing.T, failHeights map[int64]int) (*walrusQueue, *fakeUploads) {
+		f := &fakeUploads{blocks: map[int64]*types.IndexedBlock{}, failHeights: failHeights}
+		for _, b := range testBlocks {
+			f.blocks[b.BlockHeight] = b
+		}
+		db := storetest.InitTestDB(ctx, t)
+		q := newWalrusQueue(2, time.Millisecond, 5*time.Millisecond, db, f.fetch, f.upload, zerolog.Nop())
+		return q, f
+	}
+	run := func(t *testing.T, q *walrusQueue) {
+		quit := make(chan struct{})
+		var wg sync.WaitGroup
+		q.start(quit, &wg)
+		t.Cleanup(func() {
+			close(quit)
+			wg.Wait()
+		})
+	}
+	queued := func(t *testing.T, q *walrusQueue) int {
+		uploads, err := q.db.ListWalrusUploads(ctx)
+		require.NoError(t, err)
+		return len(uploads)
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		q, f := setup(t, nil)
+		for _, b := range testBlocks {
+			q.enqueue(ctx, b.MsgBlock, b.BlockHeight)
+		}
+		// queuing a block twice is a no-op
+		q.enqueue(ctx, testBlocks[0].MsgBlock, testBlocks[0].BlockHeight)
+		assert.Equal(t, 3, queued(t, q))
+
+		run(t, q)
+		require.Eventually(t, func() bool { return len(f.uploadedHeights()) == 3 }, time.Second, time.Millisecond)
+		assert.ElementsMatch(t, []int64{100, 101, 102}, f.uploadedHeights())
+		f.mu.Lock()
+		assert.Empty(t, f.fetched)
+		f.mu.Unlock()
+		assert.Eventually(t, func() bool { return queued(t, q) == 0 }, time.Second, time.Millisecond)
+		q.mu.Lock()
+		assert.Equal(t, 0, q.memBlocks)
+		q.mu.Unlock()
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		q, f := setup(t, map[int64]int{101: 2})
+		run(t, q)
+		for _, b := range testBlocks {
+			q.enqueue(ctx, b.MsgBlock, b.BlockHeight)
+		}
+
+		require.Eventually(t, func() bool { return len(f.uploadedHeights()) == 3 }, time.Second, time.Millisecond)
+		// the retries fetch the block from the node
+		f.mu.Lock()
+		assert.Equal(t, []int64{101, 101}, f.fetched)
+		f.mu.Unlock()
+		assert.Eventually(t, func() bool { return queued(t, q) == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		q, f := setup(t, nil)
+		hash101 := testBlocks[1].BlockHash()
+		for _, b := range testBlocks[1:] {
+			hash := b.BlockHash()
+			require.NoError(t, q.db.InsertWalrusUpload(ctx, hash[:], b.BlockHeight))
+		}
+		require.NoError(t, q.db.UpdateWalrusUploadAttempt(ctx, hash101[:], 3, "publisher unavailable"))
+
+		require.NoError(t, q.load(ctx))
+		q.mu.Lock()
+		assert.Len(t, q.pending, 2)
+		assert.Equal(t, int64(3), q.pending[hash101].attempts)
+		q.mu.Unlock()
+
+		run(t, q)
+		require.Eventually(t, func() bool { return len(f.uploadedHeights()) == 2 }, time.Second, time.Millisecond)
+		assert.ElementsMatch(t, []int64{101, 102}, f.uploadedHeights())
+		assert.Eventually(t, func() bool { return queued(t, q) == 0 }, time.Second, time.Millisecond)
+	})
+}
+
+func TestWalrusQueueBackoff(t *testing.T) {
+	q := newWalrusQueue(1, time.Second, 10*time.Second, nil, nil, nil, zerolog.Nop())
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 8*time.Second, q.backoff(4))
+	assert.Equal(t, 10*time.Second, q.backoff(5))
+	assert.Equal(t, 10*time.Second, q.backoff(100))
+}
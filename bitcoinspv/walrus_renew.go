@@ -0,0 +1,255 @@
+package bitcoinspv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+)
+
+// ErrWalrusEpochUnknown is returned when no Walrus epoch was observed in the store responses.
+var ErrWalrusEpochUnknown = errors.New("no Walrus epoch observed yet")
+
+// errWalrusBlockStale is returned when renewing the blob of a block that is no longer in the
+// chain.
+var errWalrusBlockStale = errors.New("block is no longer in the chain")
+
+// WalrusRenewResult reports a Walrus renewal round.
+type WalrusRenewResult struct {
+	// Failed lists the heights of the blocks whose storage couldn't be extended.
+	Failed []int64 `json:"failed"`
+	Epoch  int64   `json:"epoch"`
+	// Cost is the FROST spent by the round.
+	Cost    int64 `json:"cost"`
+	Renewed int   `json:"renewed"`
+	// Stale counts the blobs of reorganized blocks, removed from the index.
+	Stale int `json:"stale"`
+	// OverBudget counts the blobs not renewed because the budget of the epoch is spent.
+	OverBudget int `json:"over_budget"`
+}
+
+// WalrusRenewer extends the storage of the Walrus blobs of the retained blocks before they
+// expire. The publisher can't extend the storage of a blob, so the block is fetched from the
+// node and stored again for walrus-storage-epochs, and its new blob recorded. The blobs of
+// reorganized blocks are removed from the index.
+type WalrusRenewer struct {
+	walrus    *WalrusHandler
+	db        *store.DB
+	btcClient clients.BTCClient
+	cfg       *config.RelayerConfig
+	logger    zerolog.Logger
+}
+
+// NewWalrusRenewer creates a Walrus renewer configured by the walrus-renew-* settings.
+func NewWalrusRenewer(
+	wh *WalrusHandler,
+	db *store.DB,
+	btcClient clients.BTCClient,
+	cfg *config.RelayerConfig,
+	parentLogger zerolog.Logger,
+) *WalrusRenewer {
+	return &WalrusRenewer{
+		walrus:    wh,
+		db:        db,
+		btcClient: btcClient,
+		cfg:       cfg,
+		logger:    parentLogger.With().Str("module", "bitcoinspv/walrus_renew").Logger(),
+	}
+}
+
+// CurrentEpoch returns the current Walrus epoch: the last epoch observed in the store
+// responses, advanced by the walrus-epoch-duration periods elapsed since it was observed.
+func (r *WalrusRenewer) CurrentEpoch(ctx context.Context) (int64, error) {
+	epoch, err := r.currentEpoch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return epoch.Epoch, nil
+}
+
+// currentEpoch returns the current Walrus epoch with its recorded, or estimated, start.
+func (r *WalrusRenewer) currentEpoch(ctx context.Context) (store.WalrusEpoch, error) {
+	latest, err := r.db.GetLatestWalrusEpoch(ctx)
+	if err != nil {
+		return store.WalrusEpoch{}, err
+	}
+	if latest == nil {
+		return store.WalrusEpoch{}, ErrWalrusEpochUnknown
+	}
+	epoch := store.WalrusEpoch{Epoch: latest.Epoch, Timestamp: latest.Timestamp}
+	if d := r.cfg.WalrusEpochDuration; d > 0 {
+		if elapsed := time.Since(time.Unix(latest.Timestamp, 0)); elapsed >= d {
+			n := int64(elapsed / d)
+			epoch.Epoch += n
+			epoch.Timestamp += n * int64(d/time.Second)
+		}
+	}
+	return epoch, nil
+}
+
+// Expiring returns the current Walrus epoch and the blobs of the retained blocks expiring
+// within the given number of epochs, soonest expiring first. Expired blobs are included.
+func (r *WalrusRenewer) Expiring(ctx context.Context, within int64) (int64, []*store.WalrusBlob, error) {
+	epoch, err := r.CurrentEpoch(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	blobs, err := r.listExpiring(ctx, epoch+within)
+	if err != nil {
+		return 0, nil, err
+	}
+	return epoch, blobs, nil
+}
+
+// listExpiring returns the blobs of the blocks kept by walrus-retention-blocks expiring at
+// or before the given epoch.
+func (r *WalrusRenewer) listExpiring(ctx context.Context, maxEndEpoch int64) ([]*store.WalrusBlob, error) {
+	var from int64
+	if r.cfg.WalrusRetentionBlocks > 0 {
+		_, tipHeight, err := r.btcClient.GetBTCTipBlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the chain tip: %w", err)
+		}
+		from = max(tipHeight-r.cfg.WalrusRetentionBlocks+1, 0)
+	}
+	return r.db.ListExpiringWalrusBlobs(ctx, maxEndEpoch, from)
+}
+
+// Renew extends the storage of the retained blobs expiring within walrus-renew-before-epochs,
+// soonest expiring first. A renewal is skipped when its estimated cost would exceed the
+// walrus-renew-budget left in the current epoch. The cost is estimated from the blob's
+// previous cost or, for a blob that was already certified and cost nothing, from the last
+// renewal cost or the highest recorded blob cost. A block that can't be renewed is reported
+// in the result and doesn't stop the round.
+func (r *WalrusRenewer) Renew(ctx context.Context) (*WalrusRenewResult, error) {
+	within := r.cfg.WalrusRenewBeforeEpochs
+	if within == 0 {
+		within = config.DefaultWalrusRenewBeforeEpochs
+	}
+	current, err := r.currentEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := r.listExpiring(ctx, current.Epoch+within)
+	if err != nil {
+		return nil, err
+	}
+	metrics.WalrusBlobsExpiring.Set(float64(len(blobs)))
+
+	var spent int64
+	recorded, err := r.db.GetWalrusEpoch(ctx, current.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	if recorded != nil {
+		spent = recorded.RenewCost
+	}
+	// estimate of the blobs that cost nothing when stored
+	lastCost, err := r.db.GetMaxWalrusBlobCost(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &WalrusRenewResult{Epoch: current.Epoch, Failed: []int64{}}
+	fail := func(blob *store.WalrusBlob, err error) {
+		r.logger.Warn().Err(err).Int64("height", blob.Height).Str("blob_id", blob.BlobID).
+			Msg("Walrus renewal failed")
+		res.Failed = append(res.Failed, blob.Height)
+	}
+	for i, blob := range blobs {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		block, err := r.canonicalBlock(blob)
+		if errors.Is(err, errWalrusBlockStale) {
+			// the blob of the block can't be renewed anymore, nor served for its height
+			if err := r.db.DeleteWalrusBlob(ctx, blob.BlockHash); err != nil {
+				fail(blob, err)
+				continue
+			}
+			res.Stale++
+			continue
+		}
+		if err != nil {
+			fail(blob, err)
+			continue
+		}
+
+		estimate := blob.Cost
+		if estimate == 0 {
+			estimate = lastCost
+		}
+		if budget := r.cfg.WalrusRenewBudget; budget > 0 && spent+estimate > budget {
+			res.OverBudget = len(blobs) - i
+			r.logger.Warn().Int64("epoch", current.Epoch).Int64("spent", spent).Int64("budget", budget).
+				Int("blobs", res.OverBudget).Msg("Walrus renew budget spent, leaving blobs to expire")
+			break
+		}
+		cost, err := r.renew(ctx, blob, block, current)
+		if err != nil {
+			fail(blob, err)
+			continue
+		}
+		res.Renewed++
+		res.Cost += cost
+		spent += cost
+		if cost > 0 {
+			lastCost = cost
+		}
+	}
+
+	if len(blobs) > 0 {
+		r.logger.Info().Int64("epoch", current.Epoch).Int("renewed", res.Renewed).Int64("cost", res.Cost).
+			Int("failed", len(res.Failed)).Int("over_budget", res.OverBudget).Msg("Walrus renewal completed")
+	}
+	return res, nil
+}
+
+// canonicalBlock returns the block of a blob from the node, or errWalrusBlockStale when the
+// block was reorganized.
+func (r *WalrusRenewer) canonicalBlock(blob *store.WalrusBlob) (*types.IndexedBlock, error) {
+	block, err := r.btcClient.GetBTCBlockByHeight(blob.Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", blob.Height, err)
+	}
+	if hash := block.BlockHash(); !bytes.Equal(hash[:], blob.BlockHash) {
+		return nil, errWalrusBlockStale
+	}
+	return block, nil
+}
+
+// renew stores the block of an expiring blob again and records the new blob and its cost.
+func (r *WalrusRenewer) renew(
+	ctx context.Context,
+	blob *store.WalrusBlob,
+	block *types.IndexedBlock,
+	current store.WalrusEpoch,
+) (int64, error) {
+	hash := block.BlockHash()
+	stored, err := r.walrus.ArchiveBlock(block.MsgBlock, blob.Height)
+	if err != nil {
+		return 0, err
+	}
+	if stored.EndEpoch <= blob.EndEpoch {
+		return 0, fmt.Errorf("blob %s still expires at epoch %d, increase walrus-storage-epochs",
+			stored.BlobID, stored.EndEpoch)
+	}
+	if err := recordWalrusBlob(ctx, r.db, blob.Height, hash, stored); err != nil {
+		return 0, err
+	}
+	current.RenewCost = stored.Cost
+	if err := r.db.UpsertWalrusEpoch(ctx, current); err != nil {
+		return 0, err
+	}
+	metrics.WalrusBlobsRenewed.Inc()
+	metrics.WalrusRenewCost.Add(float64(stored.Cost))
+	return stored.Cost, nil
+}
@@ -0,0 +1,160 @@
+package bitcoinspv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/gonative-cc/relayer/bitcoinspv/store/storetest"
+	"github.com/gonative-cc/relayer/bitcoinspv/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalrusRenew(t *testing.T) {
+	ctx := context.Background()
+	blocks := types.CreateTestIndexedBlocks(t, 5, 100)
+
+	setup := func(t *testing.T, budget int64) (*WalrusRenewer, *fakeWalrus) {
+		f, wh := newFakeWalrus(t)
+		f.setEpochs(3, 6)
+		_, btcClient, _ := setupTest(t)
+		db := storetest.InitTestDB(ctx, t)
+		btcClient.On("GetBTCTipBlock").Return(&chainhash.Hash{}, int64(104), nil)
+		for _, b := range blocks {
+			btcClient.On("GetBTCBlockByHeight", b.BlockHeight).Return(b, nil).Maybe()
+		}
+		require.NoError(t, db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: 3, Timestamp: time.Now().Unix()}))
+		// 100 is out of the retention window, 102 was reorganized and 104 doesn't expire soon
+		endEpochs := map[int64]int64{100: 3, 101: 4, 103: 4, 104: 8}
+		for _, b := range blocks {
+			if end, ok := endEpochs[b.BlockHeight]; ok {
+				blob := &StoredBlob{BlobID: "old", Cost: 5, EndEpoch: end}
+				require.NoError(t, recordWalrusBlob(ctx, db, b.BlockHeight, b.BlockHash(), blob))
+			}
+		}
+		require.NoError(t, db.UpsertWalrusBlob(ctx, store.WalrusBlob{
+			BlockHash: []byte("reorganized"), Height: 102, BlobID: "stale", EndEpoch: 4,
+		}))
+
+		cfg := &config.RelayerConfig{WalrusRetentionBlocks: 4, WalrusRenewBudget: budget}
+		return NewWalrusRenewer(wh, db, btcClient, cfg, zerolog.Nop()), f
+	}
+	endEpoch := func(t *testing.T, r *WalrusRenewer, b *types.IndexedBlock) int64 {
+		hash := b.BlockHash()
+		blob, err := r.db.GetWalrusBlob(ctx, hash[:])
+		require.NoError(t, err)
+		return blob.EndEpoch
+	}
+
+	t.Run("renew", func(t *testing.T) {
+		r, _ := setup(t, 0)
+		epoch, expiring, err := r.Expiring(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), epoch)
+		require.Len(t, expiring, 3)
+		assert.Equal(t, int64(101), expiring[0].Height)
+
+		res, err := r.Renew(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Epoch)
+		assert.Equal(t, 2, res.Renewed)
+		assert.Equal(t, 1, res.Stale)
+		assert.Equal(t, int64(10), res.Cost)
+		assert.Empty(t, res.Failed)
+
+		assert.Equal(t, int64(3), endEpoch(t, r, blocks[0]))
+		assert.Equal(t, int64(6), endEpoch(t, r, blocks[1]))
+		assert.Equal(t, int64(6), endEpoch(t, r, blocks[3]))
+		epochRecord, err := r.db.GetWalrusEpoch(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), epochRecord.RenewCost)
+
+		// the stale blob is removed
+		_, expiring, err = r.Expiring(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, expiring)
+		stale, err := r.db.GetWalrusBlob(ctx, []byte("reorganized"))
+		require.NoError(t, err)
+		assert.Nil(t, stale)
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		r, _ := setup(t, 8)
+		res, err := r.Renew(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Renewed)
+		assert.Equal(t, 1, res.Stale)
+		assert.Equal(t, 1, res.OverBudget)
+		assert.Equal(t, int64(4), endEpoch(t, r, blocks[3]))
+
+		// the budget of the epoch is already spent
+		res, err = r.Renew(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, res.Renewed)
+		assert.Equal(t, 1, res.OverBudget)
+	})
+
+	t.Run("budget of certified blobs", func(t *testing.T) {
+		r, _ := setup(t, 8)
+		// blobs already certified when stored cost nothing
+		for _, b := range []*types.IndexedBlock{blocks[1], blocks[3]} {
+			blob := &StoredBlob{BlobID: "certified", EndEpoch: 4}
+			require.NoError(t, r.db.UpsertWalrusBlob(ctx, walrusBlobRecord(b.BlockHeight, b.BlockHash(), blob)))
+		}
+		res, err := r.Renew(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Renewed)
+		assert.Equal(t, 1, res.OverBudget)
+		assert.Equal(t, int64(5), res.Cost)
+	})
+
+	t.Run("not extended", func(t *testing.T) {
+		r, f := setup(t, 0)
+		f.setEpochs(3, 4)
+		res, err := r.Renew(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, res.Renewed)
+		assert.Equal(t, []int64{101, 103}, res.Failed)
+	})
+
+	t.Run("unknown epoch", func(t *testing.T) {
+		_, wh := newFakeWalrus(t)
+		_, btcClient, _ := setupTest(t)
+		r := NewWalrusRenewer(wh, storetest.InitTestDB(ctx, t), btcClient, &config.RelayerConfig{}, zerolog.Nop())
+		_, err := r.Renew(ctx)
+		assert.ErrorIs(t, err, ErrWalrusEpochUnknown)
+	})
+}
+
+func TestWalrusCurrentEpoch(t *testing.T) {
+	ctx := context.Background()
+	db := storetest.InitTestDB(ctx, t)
+	observed := time.Now().Add(-50 * time.Hour).Unix()
+	require.NoError(t, db.UpsertWalrusEpoch(ctx, store.WalrusEpoch{Epoch: 3, Timestamp: observed}))
+
+	r := NewWalrusRenewer(nil, db, nil, &config.RelayerConfig{}, zerolog.Nop())
+	epoch, err := r.CurrentEpoch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), epoch)
+
+	r.cfg.WalrusEpochDuration = 24 * time.Hour
+	epoch, err = r.CurrentEpoch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), epoch)
+
+	// a store response observes the next epoch
+	f, wh := newFakeWalrus(t)
+	f.setEpochs(6, 10)
+	blocks := types.CreateTestIndexedBlocks(t, 1, 100)
+	blob, err := wh.ArchiveBlock(blocks[0].MsgBlock, 100)
+	require.NoError(t, err)
+	require.NoError(t, recordWalrusBlob(ctx, db, 100, blocks[0].BlockHash(), blob))
+	epoch, err = r.CurrentEpoch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), epoch)
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/api"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcindexer"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients/btcwrapper"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/esplora"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients/p2p"
 	"github.com/gonative-cc/relayer/bitcoinspv/clients/sui"
 	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/lease"
+	"github.com/gonative-cc/relayer/bitcoinspv/metrics"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	btctypes "github.com/gonative-cc/relayer/bitcoinspv/types/btc"
+	"github.com/gonative-cc/relayer/signer"
 	"github.com/pattonkan/sui-go/suiclient"
-	"github.com/pattonkan/sui-go/suisigner"
-	"github.com/pattonkan/sui-go/suisigner/suicrypto"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +31,7 @@ var (
 )
 
 func init() {
-	rootCmd.AddCommand(CmdStart())
+	rootCmd.AddCommand(CmdStart(), CmdHeaders(), CmdLightClient(), CmdSubmit(), CmdKeys(), CmdWalrus())
 }
 
 // CmdExecute executes the root command.
@@ -36,11 +43,14 @@ func CmdExecute() error {
 func CmdStart() *cobra.Command {
 	var cfgFile = ""
 	var storeInWalrus = false
+	var metricsAddr = ""
+	var apiAddr = ""
+	var dryRun = false
 
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Runs the bitcoin-spv relayer",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			cfg, rootLogger, err := initConfig(cfgFile)
 			if err != nil {
 				return err
@@ -48,6 +58,19 @@ func CmdStart() *cobra.Command {
 			if storeInWalrus {
 				cfg.Relayer.StoreBlocksInWalrus = true
 			}
+			if metricsAddr != "" {
+				cfg.Relayer.MetricsListenAddr = metricsAddr
+			}
+			if apiAddr != "" {
+				cfg.Relayer.APIListenAddr = apiAddr
+			}
+			if dryRun {
+				cfg.Sui.DryRun = true
+			}
+			if cfg.Sui.DryRun {
+				rootLogger.Warn().Msg("Dry-run mode: header transactions are dev-inspected, the light client is not updated")
+			}
+			startMetricsServer(cfg, rootLogger)
 			btcClient, err := initBTCClient(cfg, rootLogger)
 			if err != nil {
 				return err
@@ -56,15 +79,31 @@ func CmdStart() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if p2pClient, ok := btcClient.(*p2p.Client); ok {
+				if err := checkP2PSyncStart(cmd.Context(), cfg, p2pClient, nativeClient); err != nil {
+					return err
+				}
+			}
 			walrusHandler, err := initWalrusHandler(&cfg.Relayer, rootLogger) // will return nil if flag not set
 			if err != nil {
 				return err
 			}
 			btcIndexer := initBtcIndexer(cfg, rootLogger)
+			stateStore, err := initStateStore(cfg, rootLogger) // will return nil if not configured
+			if err != nil {
+				return err
+			}
+			closeStateStoreOnShutdown(rootLogger, stateStore)
 
 			logTipBlock(btcClient, rootLogger)
 
 			spvRelayer := initSPVRelayer(cfg, rootLogger, btcClient, nativeClient, walrusHandler, btcIndexer)
+			spvRelayer.SetStateStore(stateStore)
+			spvRelayer.SetDryRun(cfg.Sui.DryRun)
+			if err := startLeaderElection(cfg, rootLogger, spvRelayer); err != nil {
+				return err
+			}
+			startAPIServer(cfg, rootLogger, spvRelayer, stateStore)
 			spvRelayer.Start()
 
 			setupShutdown(rootLogger, spvRelayer, btcClient, nativeClient)
@@ -76,6 +115,10 @@ func CmdStart() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
 	cmd.Flags().BoolVar(&storeInWalrus, "walrus", false, "enable storing full blocks in Walrus")
+	cmd.Flags().StringVar(&metricsAddr, "metrics", "", "address of the Prometheus metrics listener, e.g. :9090")
+	cmd.Flags().StringVar(&apiAddr, "api", "", "address of the health/status HTTP API listener, e.g. :8080")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"dev-inspect the header transactions instead of executing them, no gas is spent")
 	return cmd
 }
 
@@ -91,7 +134,31 @@ func initConfig(cfgFile string) (*config.Config, zerolog.Logger, error) {
 	return &cfg, rootLogger, nil
 }
 
-func initBTCClient(cfg *config.Config, rootLogger zerolog.Logger) (*btcwrapper.Client, error) {
+func initBTCClient(cfg *config.Config, rootLogger zerolog.Logger) (clients.BTCClient, error) {
+	switch cfg.BTC.BtcBackend {
+	case btctypes.Esplora:
+		esploraClient, err := esplora.New(
+			&cfg.BTC,
+			cfg.Relayer.RetrySleepDuration,
+			cfg.Relayer.MaxRetrySleepDuration,
+			rootLogger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open Esplora client: %w", err)
+		}
+		return esploraClient, nil
+	case btctypes.P2P:
+		p2pClient, err := p2p.New(
+			&cfg.BTC,
+			cfg.Relayer.RetrySleepDuration,
+			cfg.Relayer.MaxRetrySleepDuration,
+			rootLogger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open P2P client: %w", err)
+		}
+		return p2pClient, nil
+	}
 	btcClient, err := btcwrapper.NewClientWithBlockSubscriber(
 		&cfg.BTC,
 		cfg.Relayer.RetrySleepDuration,
@@ -104,28 +171,68 @@ func initBTCClient(cfg *config.Config, rootLogger zerolog.Logger) (*btcwrapper.C
 	return btcClient, nil
 }
 
-func logTipBlock(btcClient *btcwrapper.Client, rootLogger zerolog.Logger) {
-	latestBTCBlock, err := btcClient.GetTipBlock()
+// checkP2PSyncStart fails when the relayer needs headers older than the start of the p2p
+// headers sync: the bootstrap fetches the headers from the light client tip minus the
+// confirmation depth, and the header validation their ancestors of a difficulty period.
+func checkP2PSyncStart(
+	ctx context.Context,
+	cfg *config.Config,
+	p2pClient *p2p.Client,
+	nativeClient clients.BitcoinSPV,
+) error {
+	lcBlock, err := nativeClient.GetLatestBlockInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get light client tip: %w", err)
+	}
+	needed := lcBlock.Height - cfg.Relayer.BTCConfirmationDepth + 1
+	if !cfg.Relayer.SkipHeaderValidation {
+		params, err := btctypes.GetChainParams(cfg.BTC.NetParams)
+		if err != nil {
+			return err
+		}
+		needed -= int64(params.TargetTimespan / params.TargetTimePerBlock)
+	}
+	// the genesis block is the base of the chains without checkpoints
+	if base := p2pClient.BaseHeight(); base > 0 && needed <= base {
+		return fmt.Errorf("the light client at height %d needs the headers from height %d, "+
+			"the p2p headers sync starts after height %d: set p2p-start-height to %d or lower",
+			lcBlock.Height, needed, base, max(needed-1, 1))
+	}
+	return nil
+}
+
+func logTipBlock(btcClient clients.BTCClient, rootLogger zerolog.Logger) {
+	hash, height, err := btcClient.GetBTCTipBlock()
 	if err != nil {
 		panic(fmt.Errorf("failed to get chain tip block: %w", err))
 	}
 
 	rootLogger.Info().
-		Str("hash", latestBTCBlock.Hash).
-		Int64("height", latestBTCBlock.Height).
-		Int64("time", latestBTCBlock.Time).
+		Str("hash", hash.String()).
+		Int64("height", height).
 		Msg("Got tip block")
 }
 
 func initNativeClient(cfg *config.Config, rootLogger zerolog.Logger) (clients.BitcoinSPV, error) {
 	c := suiclient.NewClient(cfg.Sui.Endpoint)
 
-	signer, err := suisigner.NewSignerWithMnemonic(cfg.Sui.Mnemonic, suicrypto.KeySchemeFlagDefault)
+	signerCfg := cfg.Sui.SignerConfig()
+	if signerCfg.Source == signer.SourceMnemonic {
+		rootLogger.Warn().Msg("Reading the Sui key from the mnemonic in the config file, " +
+			"prefer one of the sui.signer sources")
+	}
+	txSigner, err := signer.New(signerCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new signer: %w", err)
 	}
 
-	client, err := sui.New(c, signer, cfg.Sui.LCObjectID, cfg.Sui.LCPkgID, cfg.Sui.BTCLibPkgID, rootLogger)
+	gasCfg := sui.GasConfig{
+		BudgetMargin: cfg.Sui.GasBudgetMargin,
+		MaxBudget:    cfg.Sui.MaxGasBudget,
+		LowBalance:   cfg.Sui.LowBalanceThreshold,
+	}
+	client, err := sui.New(
+		c, txSigner, cfg.Sui.LCObjectID, cfg.Sui.LCPkgID, cfg.Sui.BTCLibPkgID, cfg.Sui.DryRun, gasCfg, rootLogger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new bitcoinSPVClient: %w", err)
 	}
@@ -150,10 +257,121 @@ func initBtcIndexer(cfg *config.Config, rootLogger zerolog.Logger) btcindexer.In
 	return client
 }
 
+func initStateStore(cfg *config.Config, rootLogger zerolog.Logger) (*store.DB, error) {
+	if cfg.Relayer.StateDBFile == "" {
+		rootLogger.Info().Msg("State DB not configured, sync checkpoints won't be persisted.")
+		return nil, nil
+	}
+	db, err := store.NewDB(cfg.Relayer.StateDBFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state DB: %w", err)
+	}
+	if err := db.InitDB(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to initialize state DB: %w", err)
+	}
+	return db, nil
+}
+
+// closeStateStoreOnShutdown closes the state DB on shutdown. Handlers run in LIFO order, so
+// it's registered before the API server and the relayer, which use the DB until they stop.
+func closeStateStoreOnShutdown(rootLogger zerolog.Logger, stateStore *store.DB) {
+	if stateStore == nil {
+		return
+	}
+	registerHandler(func() {
+		rootLogger.Info().Msg("Closing state DB...")
+		if err := stateStore.Close(); err != nil {
+			rootLogger.Err(err).Msg("Failed to close state DB")
+		}
+	})
+}
+
+func startMetricsServer(cfg *config.Config, rootLogger zerolog.Logger) {
+	if cfg.Relayer.MetricsListenAddr == "" {
+		return
+	}
+	srv := metrics.StartServer(cfg.Relayer.MetricsListenAddr, rootLogger)
+	registerHandler(func() {
+		rootLogger.Info().Msg("Stopping metrics server...")
+		if err := srv.Shutdown(context.Background()); err != nil {
+			rootLogger.Err(err).Msg("Failed to stop metrics server")
+		}
+	})
+}
+
+// startAPIServer starts the relayer HTTP API. Returns nil if the API is not configured.
+func startAPIServer(
+	cfg *config.Config,
+	rootLogger zerolog.Logger,
+	spvRelayer *bitcoinspv.Relayer,
+	stateStore *store.DB,
+) *api.Server {
+	if cfg.Relayer.APIListenAddr == "" {
+		return nil
+	}
+	srv := api.NewServer(cfg.Relayer.APIListenAddr, spvRelayer, rootLogger)
+	if cfg.Relayer.ProofAPI {
+		srv.Handle("GET /proof/", api.NewProofHandler(spvRelayer, rootLogger))
+	}
+	if stateStore != nil {
+		srv.Handle("GET /walrus/", api.NewWalrusHandler(stateStore, rootLogger))
+	}
+	srv.Start()
+	registerHandler(func() {
+		rootLogger.Info().Msg("Stopping API server...")
+		if err := srv.Shutdown(context.Background()); err != nil {
+			rootLogger.Err(err).Msg("Failed to stop API server")
+		}
+	})
+	return srv
+}
+
+// startLeaderElection starts competing for the lease with the other relayer instances
+// and makes the relayer submit headers only while it's the leader.
+// It's a no-op if the lease backend is not configured.
+func startLeaderElection(cfg *config.Config, rootLogger zerolog.Logger, spvRelayer *bitcoinspv.Relayer) error {
+	if cfg.Relayer.LeaseBackend == "" {
+		return nil
+	}
+	backend, err := lease.NewBackend(cfg.Relayer.LeaseBackend, cfg.Relayer.LeasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open lease backend: %w", err)
+	}
+	holder := cfg.Relayer.LeaseHolder
+	if holder == "" {
+		holder = lease.DefaultHolder()
+	}
+	elector, err := lease.NewElector(backend, holder, cfg.Relayer.LeaseTTL, rootLogger)
+	if err != nil {
+		backend.Close()
+		return err
+	}
+	spvRelayer.SetLeaderElector(elector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		elector.Run(ctx)
+		close(done)
+	}()
+	// handlers run in LIFO order, so the lease is released after the relayer stops
+	registerHandler(func() {
+		rootLogger.Info().Msg("Releasing lease...")
+		cancel()
+		<-done
+		if err := backend.Close(); err != nil {
+			rootLogger.Err(err).Msg("Failed to close lease backend")
+		}
+	})
+	rootLogger.Info().Str("backend", cfg.Relayer.LeaseBackend).Str("holder", holder).
+		Msg("Leader election enabled")
+	return nil
+}
+
 func initSPVRelayer(
 	cfg *config.Config,
 	rootLogger zerolog.Logger,
-	btcClient *btcwrapper.Client,
+	btcClient clients.BTCClient,
 	nativeClient clients.BitcoinSPV,
 	walrusHandler *bitcoinspv.WalrusHandler,
 	btcIndexer btcindexer.Indexer,
@@ -176,14 +394,9 @@ func initSPVRelayer(
 func setupShutdown(
 	rootLogger zerolog.Logger,
 	spvRelayer *bitcoinspv.Relayer,
-	btcClient *btcwrapper.Client,
+	btcClient clients.BTCClient,
 	nativeClient clients.BitcoinSPV,
 ) {
-	registerHandler(func() {
-		rootLogger.Info().Msg("Stopping relayer...")
-		spvRelayer.Stop()
-		rootLogger.Info().Msg("Relayer shutdown")
-	})
 	registerHandler(func() {
 		rootLogger.Info().Msg("Stopping BTC client...")
 		btcClient.Stop()
@@ -195,4 +408,12 @@ func setupShutdown(
 		nativeClient.Stop()
 		rootLogger.Info().Msg("Native client shutdown")
 	})
+	// handlers run in LIFO order: the relayer goroutines complete their calls to the clients
+	// before the clients stop
+	registerHandler(func() {
+		rootLogger.Info().Msg("Stopping relayer...")
+		spvRelayer.Stop()
+		spvRelayer.WaitForShutdown()
+		rootLogger.Info().Msg("Relayer shutdown")
+	})
 }
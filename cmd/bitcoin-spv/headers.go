@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/headerfile"
+	"github.com/spf13/cobra"
+)
+
+// CmdHeaders returns the CLI commands to export headers from the Bitcoin node to a file and
+// to submit the headers of a file to the light client.
+func CmdHeaders() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "headers",
+		Short: "Export and replay Bitcoin headers",
+	}
+	cmd.AddCommand(cmdHeadersExport(), cmdHeadersSubmit())
+	return cmd
+}
+
+func cmdHeadersExport() *cobra.Command {
+	var cfgFile, out, format string
+	var from, to int64
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the headers of a height range from the Bitcoin node to a file",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(cfgFile)
+			if err != nil {
+				return err
+			}
+			btcClient, err := initBTCClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer btcClient.Stop()
+
+			if to == 0 {
+				if _, to, err = btcClient.GetBTCTipBlock(); err != nil {
+					return fmt.Errorf("failed to get chain tip block: %w", err)
+				}
+			}
+			if from < 0 || from > to {
+				return fmt.Errorf("invalid height range [%d, %d]", from, to)
+			}
+
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w, err := headerfile.NewWriter(f, format)
+			if err != nil {
+				return err
+			}
+			for height := from; height <= to; height++ {
+				header, err := btcClient.GetBTCBlockHeaderByHeight(height)
+				if err != nil {
+					return fmt.Errorf("failed to get header at height %d: %w", height, err)
+				}
+				if err := w.Write(height, header); err != nil {
+					return err
+				}
+				if (height-from+1)%1000 == 0 {
+					rootLogger.Info().Msgf("Exported %d/%d headers...", height-from+1, to-from+1)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			rootLogger.Info().Int64("from", from).Int64("to", to).Str("file", out).Msg("Headers exported")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
+	cmd.Flags().Int64Var(&from, "from", 0, "first height to export")
+	cmd.Flags().Int64Var(&to, "to", 0, "last height to export, defaults to the node tip")
+	cmd.Flags().StringVar(&out, "out", "", "output file")
+	cmd.Flags().StringVar(&format, "format", headerfile.FormatHex, "file format (hex|raw)")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("out")
+	return cmd
+}
+
+func cmdHeadersSubmit() *cobra.Command {
+	var cfgFile, file, format string
+
+	cmd := &cobra.Command{
+		Use:   "submit",
+		Short: "Submits the headers of a file to the light client, without a Bitcoin node",
+		Long: `Submits the headers of a file to the light client, without a Bitcoin node.
+Headers already known to the light client are skipped. The local header validation needs
+the Bitcoin node and is disabled: the headers are still verified by the light client.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(cfgFile)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			blocks, err := headerfile.Read(f, format)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			if len(blocks) == 0 {
+				return fmt.Errorf("no headers in %s", file)
+			}
+
+			nativeClient, err := initNativeClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer nativeClient.Stop()
+
+			cfg.Relayer.SkipHeaderValidation = true
+			spvRelayer, err := bitcoinspv.New(&cfg.Relayer, rootLogger, nil, nativeClient, nil, nil)
+			if err != nil {
+				return fmt.Errorf("failed to create bitcoin-spv relayer: %w", err)
+			}
+			submitted, err := spvRelayer.ProcessHeaders(cmd.Context(), blocks)
+			if err != nil {
+				return err
+			}
+			rootLogger.Info().
+				Int64("from", blocks[0].BlockHeight).
+				Int64("to", blocks[len(blocks)-1].BlockHeight).
+				Int("submitted", submitted).
+				Msg("Headers submitted")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
+	cmd.Flags().StringVar(&file, "file", "", "header file written by headers export")
+	cmd.Flags().StringVar(&format, "format", headerfile.FormatHex, "file format (hex|raw)")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
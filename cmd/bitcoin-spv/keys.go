@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/signer"
+	"github.com/spf13/cobra"
+)
+
+// CmdKeys returns the CLI commands managing the Sui signing key.
+func CmdKeys() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manages the Sui signing key",
+	}
+	cmd.AddCommand(cmdKeysEncrypt(), cmdKeysAddress(), cmdKeysServe())
+	return cmd
+}
+
+func cmdKeysEncrypt() *cobra.Command {
+	var keystore, address, alias, passphraseEnv, out string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypts a key of a Sui CLI keystore with a passphrase",
+		Long: `Encrypts a key of a Sui CLI keystore with a passphrase, for the encrypted-keystore signer
+source. The passphrase is read from the passphrase environment variable.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			key, err := signer.ReadKeystore(keystore, address, alias)
+			if err != nil {
+				return err
+			}
+			passphrase, err := signer.Passphrase(passphraseEnv)
+			if err != nil {
+				return err
+			}
+			data, err := signer.EncryptKey(key, passphrase)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, data, 0o600); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Key encrypted to %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keystore, "keystore", "", "Sui CLI keystore, e.g. ~/.sui/sui_config/sui.keystore")
+	cmd.Flags().StringVar(&address, "address", "", "address of the key to encrypt")
+	cmd.Flags().StringVar(&alias, "alias", "", "alias of the key to encrypt")
+	cmd.Flags().StringVar(&passphraseEnv, "passphrase-env", signer.DefaultPassphraseEnvVar,
+		"environment variable with the passphrase")
+	cmd.Flags().StringVar(&out, "out", "", "encrypted keystore file")
+	_ = cmd.MarkFlagRequired("keystore")
+	_ = cmd.MarkFlagRequired("out")
+	return cmd
+}
+
+func cmdKeysAddress() *cobra.Command {
+	var cfgFile string
+
+	cmd := &cobra.Command{
+		Use:   "address",
+		Short: "Prints the Sui address of the configured signer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, _, err := initConfig(cfgFile)
+			if err != nil {
+				return err
+			}
+			s, err := signer.New(cfg.Sui.SignerConfig())
+			if err != nil {
+				return fmt.Errorf("failed to create new signer: %w", err)
+			}
+			return printOutput(cmd, struct {
+				Address string `json:"address"`
+			}{s.Address().String()}, func(w io.Writer) {
+				fmt.Fprintln(w, s.Address())
+			})
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
+	cmd.Flags().Bool("json", false, "print the output as JSON")
+	return cmd
+}
+
+func cmdKeysServe() *cobra.Command {
+	var cfgFile, listen, tokenEnv string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serves the configured signer as a remote signing service",
+		Long: `Serves the configured signer as a remote signing service, for the remote signer source.
+It's a reference implementation: production setups should run the signing service on a
+dedicated host. Requests are authenticated with the token of the token environment variable.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(cfgFile)
+			if err != nil {
+				return err
+			}
+			signerCfg := cfg.Sui.SignerConfig()
+			if signerCfg.Source == signer.SourceRemote {
+				return errors.New("the signing service can't use the remote signer source")
+			}
+			s, err := signer.New(signerCfg)
+			if err != nil {
+				return fmt.Errorf("failed to create new signer: %w", err)
+			}
+			token := os.Getenv(tokenEnv)
+			if token == "" {
+				rootLogger.Warn().Msgf("%s is not set: requests are not authenticated", tokenEnv)
+			}
+
+			srv := &http.Server{
+				Addr:              listen,
+				Handler:           signer.NewServer(s, token, rootLogger),
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+			registerHandler(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := srv.Shutdown(ctx); err != nil {
+					rootLogger.Err(err).Msg("Failed to shut down the signing service")
+				}
+			})
+			rootLogger.Info().Str("addr", listen).Str("address", s.Address().String()).
+				Msg("Serving signing service")
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			<-interruptDone
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:9095", "listen address")
+	cmd.Flags().StringVar(&tokenEnv, "token-env", signer.DefaultTokenEnvVar,
+		"environment variable with the token authenticating the requests")
+	return cmd
+}
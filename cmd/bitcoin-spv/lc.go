@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/spf13/cobra"
+)
+
+// CmdLightClient returns the read-only CLI commands inspecting the light client.
+func CmdLightClient() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lc",
+		Short: "Inspects the Bitcoin light client",
+	}
+	cmd.PersistentFlags().String("config", config.DefaultCfgFile(), "config file")
+	cmd.PersistentFlags().Bool("json", false, "print the output as JSON")
+	cmd.AddCommand(cmdLCHead(), cmdLCContains(), cmdLCDiff())
+	return cmd
+}
+
+// blockRef is a block of the light client or the Bitcoin node.
+type blockRef struct {
+	Hash      string `json:"hash"`
+	Height    int64  `json:"height"`
+	ChainWork string `json:"chainwork,omitempty"`
+}
+
+// heightRange is an inclusive range of block heights.
+type heightRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+func (r heightRange) String() string {
+	if r.From == r.To {
+		return fmt.Sprint(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// lcDiff compares the light client with the Bitcoin node.
+type lcDiff struct {
+	LCTip   blockRef `json:"lc_tip"`
+	NodeTip blockRef `json:"node_tip"`
+	// LCTipInNodeChain is false when the light client tip is not in the node best chain.
+	LCTipInNodeChain bool `json:"lc_tip_in_node_chain"`
+	// Missing are the heights of the node best chain unknown to the light client.
+	Missing []heightRange `json:"missing"`
+}
+
+func cmdLCHead() *cobra.Command {
+	return &cobra.Command{
+		Use:   "head",
+		Short: "Prints the hash, height and chain work of the light client tip",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			lcClient, err := initNativeClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer lcClient.Stop()
+
+			info, err := lcClient.GetLatestBlockInfo(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get light client tip: %w", err)
+			}
+			head := blockRef{Hash: info.Hash.String(), Height: info.Height}
+			if info.ChainWork != nil {
+				head.ChainWork = info.ChainWork.String()
+			}
+			return printOutput(cmd, head, func(w io.Writer) {
+				fmt.Fprintf(w, "hash:      %s\nheight:    %d\nchainwork: %s\n", head.Hash, head.Height, head.ChainWork)
+			})
+		},
+	}
+}
+
+func cmdLCContains() *cobra.Command {
+	return &cobra.Command{
+		Use:   "contains <block hash>",
+		Short: "Checks if the light client knows a block header",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash, err := chainhash.NewHashFromStr(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid block hash: %w", err)
+			}
+			cfg, rootLogger, err := initConfig(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			lcClient, err := initNativeClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer lcClient.Stop()
+
+			exists, err := lcClient.ContainsBlock(cmd.Context(), *hash)
+			if err != nil {
+				return fmt.Errorf("failed to check block %s: %w", hash, err)
+			}
+			out := struct {
+				Hash     string `json:"hash"`
+				Contains bool   `json:"contains"`
+			}{hash.String(), exists}
+			return printOutput(cmd, out, func(w io.Writer) {
+				fmt.Fprintln(w, exists)
+			})
+		},
+	}
+}
+
+func cmdLCDiff() *cobra.Command {
+	var depth int64
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compares the light client tip with the Bitcoin node tip and lists the missing heights",
+		Long: `Compares the light client tip with the Bitcoin node tip and lists the heights of the node
+best chain unknown to the light client. Heights above the light client tip are always missing,
+the headers from depth blocks below the light client tip are checked.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			lcClient, err := initNativeClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer lcClient.Stop()
+			btcClient, err := initBTCClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer btcClient.Stop()
+
+			info, err := lcClient.GetLatestBlockInfo(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get light client tip: %w", err)
+			}
+			nodeHash, nodeHeight, err := btcClient.GetBTCTipBlock()
+			if err != nil {
+				return fmt.Errorf("failed to get node tip: %w", err)
+			}
+			diff := lcDiff{
+				LCTip:   blockRef{Hash: info.Hash.String(), Height: info.Height},
+				NodeTip: blockRef{Hash: nodeHash.String(), Height: nodeHeight},
+				Missing: []heightRange{},
+			}
+
+			// the light client can't know node blocks above its tip
+			from := max(info.Height-depth, 0)
+			to := min(info.Height, nodeHeight)
+			heights := make([]int64, 0, max(to-from+1, 0))
+			hashes := make([]chainhash.Hash, 0, cap(heights))
+			for h := from; h <= to; h++ {
+				header, err := btcClient.GetBTCBlockHeaderByHeight(h)
+				if err != nil {
+					return fmt.Errorf("failed to get node header at height %d: %w", h, err)
+				}
+				heights = append(heights, h)
+				hashes = append(hashes, header.BlockHash())
+				if h == info.Height {
+					diff.LCTipInNodeChain = header.BlockHash() == *info.Hash
+				}
+			}
+			known, err := lcClient.ContainsBlocks(cmd.Context(), hashes)
+			if err != nil {
+				return fmt.Errorf("failed to check node headers: %w", err)
+			}
+			for i, ok := range known {
+				if !ok {
+					diff.Missing = appendHeight(diff.Missing, heights[i])
+				}
+			}
+			if nodeHeight > info.Height {
+				diff.Missing = appendRange(diff.Missing, heightRange{info.Height + 1, nodeHeight})
+			}
+
+			return printOutput(cmd, diff, func(w io.Writer) {
+				fmt.Fprintf(w, "lc tip:   %d %s\n", diff.LCTip.Height, diff.LCTip.Hash)
+				fmt.Fprintf(w, "node tip: %d %s\n", diff.NodeTip.Height, diff.NodeTip.Hash)
+				fmt.Fprintf(w, "lc tip in node chain: %t\n", diff.LCTipInNodeChain)
+				missing := []string{"none"}
+				if len(diff.Missing) > 0 {
+					missing = make([]string, len(diff.Missing))
+					for i, r := range diff.Missing {
+						missing[i] = r.String()
+					}
+				}
+				fmt.Fprintf(w, "missing heights: %s\n", strings.Join(missing, ", "))
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&depth, "depth", 10, "number of heights below the light client tip to check")
+	return cmd
+}
+
+// appendHeight adds a height to ranges sorted by height, merging consecutive heights.
+func appendHeight(ranges []heightRange, h int64) []heightRange {
+	return appendRange(ranges, heightRange{h, h})
+}
+
+func appendRange(ranges []heightRange, r heightRange) []heightRange {
+	if n := len(ranges); n > 0 && ranges[n-1].To+1 >= r.From {
+		ranges[n-1].To = max(ranges[n-1].To, r.To)
+		return ranges
+	}
+	return append(ranges, r)
+}
+
+func flagString(cmd *cobra.Command, name string) string {
+	v, _ := cmd.Flags().GetString(name)
+	return v
+}
+
+// printOutput prints v as JSON when the --json flag is set, or with printText otherwise.
+func printOutput(cmd *cobra.Command, v any, printText func(w io.Writer)) error {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	printText(cmd.OutOrStdout())
+	return nil
+}
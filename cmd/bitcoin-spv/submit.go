@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/spf13/cobra"
+)
+
+// CmdSubmit returns the CLI command submitting a height range of the Bitcoin node to the
+// light client.
+func CmdSubmit() *cobra.Command {
+	var cfgFile string
+	var from, to int64
+
+	cmd := &cobra.Command{
+		Use:   "submit",
+		Short: "Submits the headers of a height range from the Bitcoin node to the light client",
+		Long: `Submits the headers of a height range from the Bitcoin node to the light client, to fill
+gaps without restarting the relayer. Headers already known to the light client are skipped,
+the others are submitted in chunks. Prints the transaction digest of each submitted chunk.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, rootLogger, err := initConfig(cfgFile)
+			if err != nil {
+				return err
+			}
+			btcClient, err := initBTCClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer btcClient.Stop()
+			nativeClient, err := initNativeClient(cfg, rootLogger)
+			if err != nil {
+				return err
+			}
+			defer nativeClient.Stop()
+
+			spvRelayer, err := bitcoinspv.New(&cfg.Relayer, rootLogger, btcClient, nativeClient, nil, nil)
+			if err != nil {
+				return fmt.Errorf("failed to create bitcoin-spv relayer: %w", err)
+			}
+			submitted, submitErr := spvRelayer.SubmitRange(cmd.Context(), from, to)
+			// report the chunks submitted before a failure as well
+			if err := printOutput(cmd, submitted, func(w io.Writer) {
+				if len(submitted) == 0 && submitErr == nil {
+					fmt.Fprintln(w, "all headers already known to the light client")
+				}
+				for _, c := range submitted {
+					fmt.Fprintf(w, "%d-%d %s\n", c.From, c.To, c.TxDigest)
+				}
+			}); err != nil {
+				return err
+			}
+			return submitErr
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultCfgFile(), "config file")
+	cmd.Flags().Int64Var(&from, "from", 0, "first height to submit")
+	cmd.Flags().Int64Var(&to, "to", 0, "last height to submit")
+	cmd.Flags().Bool("json", false, "print the output as JSON")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gonative-cc/relayer/bitcoinspv"
+	"github.com/gonative-cc/relayer/bitcoinspv/clients"
+	"github.com/gonative-cc/relayer/bitcoinspv/config"
+	"github.com/gonative-cc/relayer/bitcoinspv/store"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+// CmdWalrus returns the CLI commands managing the blocks stored in Walrus.
+func CmdWalrus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "walrus",
+		Short: "Manages the blocks stored in Walrus",
+	}
+	cmd.PersistentFlags().String("config", config.DefaultCfgFile(), "config file")
+	cmd.PersistentFlags().Bool("json", false, "print the output as JSON")
+	cmd.AddCommand(cmdWalrusBlobs(), cmdWalrusAudit(), cmdWalrusBackfill(), cmdWalrusExpiring(), cmdWalrusRenew())
+	return cmd
+}
+
+func cmdWalrusBlobs() *cobra.Command {
+	var from, to int64
+	var hashStr string
+
+	cmd := &cobra.Command{
+		Use:   "blobs",
+		Short: "Prints the Walrus blob IDs of the stored blocks",
+		Long: `Prints the Walrus blob IDs of the blocks stored in Walrus by the relayer, from the state DB.
+Blocks are selected by height range (--from, --to) or by hash (--hash). The raw blocks are
+read from a Walrus aggregator with the blob IDs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if hashStr == "" && from < 0 {
+				return errors.New("set --from or --hash")
+			}
+			db, err := openStateStore(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var blobs []*store.WalrusBlob
+			if hashStr != "" {
+				hash, err := chainhash.NewHashFromStr(hashStr)
+				if err != nil {
+					return fmt.Errorf("invalid block hash: %w", err)
+				}
+				blob, err := db.GetWalrusBlob(cmd.Context(), hash[:])
+				if err != nil {
+					return err
+				}
+				if blob == nil {
+					return fmt.Errorf("block %s not stored in Walrus", hash)
+				}
+				blobs = append(blobs, blob)
+			} else {
+				if to < 0 {
+					to = from
+				}
+				if to < from {
+					return fmt.Errorf("invalid height range %d-%d", from, to)
+				}
+				if blobs, err = db.ListWalrusBlobs(cmd.Context(), from, to); err != nil {
+					return err
+				}
+			}
+
+			infos := make([]bitcoinspv.WalrusBlobInfo, len(blobs))
+			for i := range blobs {
+				infos[i] = bitcoinspv.NewWalrusBlobInfo(blobs[i])
+			}
+			return printOutput(cmd, infos, func(w io.Writer) {
+				for _, b := range infos {
+					fmt.Fprintf(w, "%d %s %s end_epoch=%d cost=%d\n", b.Height, b.BlockHash, b.BlobID, b.EndEpoch, b.Cost)
+				}
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&from, "from", -1, "first block height")
+	cmd.Flags().Int64Var(&to, "to", -1, "last block height, defaults to --from")
+	cmd.Flags().StringVar(&hashStr, "hash", "", "block hash")
+	return cmd
+}
+
+func cmdWalrusAudit() *cobra.Command {
+	var from, to int64
+	var sample int
+	var repair bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Checks that the stored blocks can be read back from Walrus",
+		Long: `Checks that the blocks of random heights of the archived range can be read back from the
+Walrus aggregators and hold the blocks of the Bitcoin node. Blocks without a recorded blob,
+with a blob that can't be read or that holds other data are reported, and uploaded again
+with --repair. The range defaults to the last block stored in Walrus.
+With --interval, the audit runs periodically until interrupted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if from < 0 {
+				return errors.New("set --from")
+			}
+			tools, err := initWalrusTools(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			defer tools.close()
+			db, rootLogger := tools.db, tools.logger
+			auditor := bitcoinspv.NewWalrusAuditor(tools.walrus, db, tools.btcClient, rootLogger)
+
+			audit := func(ctx context.Context) error {
+				last := to
+				if last < 0 {
+					cp, err := db.GetCheckpoint(ctx, store.CheckpointWalrus)
+					if err != nil {
+						return err
+					}
+					if cp == nil {
+						return errors.New("no block stored in Walrus yet, set --to")
+					}
+					last = cp.Height
+				}
+				entries, err := auditor.Audit(ctx, from, last, sample, repair)
+				if err != nil {
+					return err
+				}
+				return printOutput(cmd, entries, func(w io.Writer) {
+					for _, e := range entries {
+						fmt.Fprintf(w, "%d %s %s %s", e.Height, e.BlockHash, e.Status, e.BlobID)
+						if e.Repaired {
+							fmt.Fprintf(w, " repaired=%s", e.NewBlobID)
+						}
+						if e.Error != "" {
+							fmt.Fprintf(w, " error=%q", e.Error)
+						}
+						fmt.Fprintln(w)
+					}
+				})
+			}
+			if interval <= 0 {
+				return audit(cmd.Context())
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			registerHandler(cancel)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := audit(ctx); err != nil && ctx.Err() == nil {
+					rootLogger.Err(err).Msg("Walrus audit failed")
+				}
+				select {
+				case <-ctx.Done():
+					<-interruptDone
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	cmd.Flags().Int64Var(&from, "from", -1, "first block height")
+	cmd.Flags().Int64Var(&to, "to", -1, "last block height, defaults to the last block stored in Walrus")
+	cmd.Flags().IntVar(&sample, "sample", 20, "number of random heights to check, 0 checks all heights")
+	cmd.Flags().BoolVar(&repair, "repair", false, "upload the missing and mismatching blocks again")
+	cmd.Flags().DurationVar(&interval, "interval", 0, "run the audit periodically with this interval")
+	return cmd
+}
+
+func cmdWalrusBackfill() *cobra.Command {
+	var from, to int64
+	var workers int
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Stores the blocks of a height range in Walrus",
+		Long: `Fetches the blocks of a height range from the Bitcoin node and stores them in Walrus,
+recording their blobs in the state DB. Heights with a recorded blob are skipped. The range
+defaults to the chain tip. The blocks that can't be stored are reported and the command fails,
+it can be run again to store them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if from < 0 {
+				return errors.New("set --from")
+			}
+			tools, err := initWalrusTools(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			defer tools.close()
+			if to < 0 {
+				if _, to, err = tools.btcClient.GetBTCTipBlock(); err != nil {
+					return fmt.Errorf("failed to get chain tip block: %w", err)
+				}
+			}
+			if workers == 0 {
+				workers = tools.cfg.Relayer.WalrusUploadWorkers
+			}
+			if workers == 0 {
+				workers = config.DefaultWalrusUploadWorkers
+			}
+
+			backfiller := bitcoinspv.NewWalrusBackfiller(tools.walrus, tools.db, tools.btcClient, workers, tools.logger)
+			res, err := backfiller.Backfill(cmd.Context(), from, to)
+			if err != nil {
+				return err
+			}
+			if err := printOutput(cmd, res, func(w io.Writer) {
+				fmt.Fprintf(w, "%d-%d archived=%d skipped=%d failed=%d\n",
+					res.From, res.To, res.Archived, res.Skipped, len(res.Failed))
+				for _, h := range res.Failed {
+					fmt.Fprintf(w, "failed %d\n", h)
+				}
+			}); err != nil {
+				return err
+			}
+			if len(res.Failed) > 0 {
+				return fmt.Errorf("%d blocks couldn't be stored", len(res.Failed))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&from, "from", -1, "first block height")
+	cmd.Flags().Int64Var(&to, "to", -1, "last block height, defaults to the chain tip")
+	cmd.Flags().IntVar(&workers, "workers", 0, "number of concurrent uploads, defaults to walrus-upload-workers")
+	return cmd
+}
+
+func cmdWalrusExpiring() *cobra.Command {
+	var within int64
+
+	cmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "Prints the stored blocks whose Walrus storage expires soon",
+		Long: `Prints the blobs of the blocks retained by walrus-retention-blocks whose storage expires
+within --within Walrus epochs, soonest expiring first. The current epoch is the last epoch
+observed in the Walrus store responses, advanced by walrus-epoch-duration.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			tools, err := initWalrusTools(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			defer tools.close()
+			if within < 0 {
+				within = tools.cfg.Relayer.WalrusRenewBeforeEpochs
+			}
+			if within == 0 {
+				within = config.DefaultWalrusRenewBeforeEpochs
+			}
+
+			renewer := bitcoinspv.NewWalrusRenewer(tools.walrus, tools.db, tools.btcClient, &tools.cfg.Relayer, tools.logger)
+			epoch, blobs, err := renewer.Expiring(cmd.Context(), within)
+			if err != nil {
+				return err
+			}
+			infos := make([]bitcoinspv.WalrusBlobInfo, len(blobs))
+			for i := range blobs {
+				infos[i] = bitcoinspv.NewWalrusBlobInfo(blobs[i])
+			}
+			return printOutput(cmd, infos, func(w io.Writer) {
+				fmt.Fprintf(w, "epoch %d, %d blobs expiring by epoch %d\n", epoch, len(infos), epoch+within)
+				for _, b := range infos {
+					fmt.Fprintf(w, "%d %s %s end_epoch=%d\n", b.Height, b.BlockHash, b.BlobID, b.EndEpoch)
+				}
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&within, "within", -1, "number of epochs, defaults to walrus-renew-before-epochs")
+	return cmd
+}
+
+func cmdWalrusRenew() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "renew",
+		Short: "Extends the Walrus storage of the blocks expiring soon",
+		Long: `Stores again the retained blocks whose Walrus storage expires within
+walrus-renew-before-epochs, for walrus-storage-epochs, within the walrus-renew-budget of the
+current epoch. This is the renewal round run every walrus-renew-interval by the relayer.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			tools, err := initWalrusTools(flagString(cmd, "config"))
+			if err != nil {
+				return err
+			}
+			defer tools.close()
+
+			renewer := bitcoinspv.NewWalrusRenewer(tools.walrus, tools.db, tools.btcClient, &tools.cfg.Relayer, tools.logger)
+			res, err := renewer.Renew(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := printOutput(cmd, res, func(w io.Writer) {
+				fmt.Fprintf(w, "epoch %d renewed=%d cost=%d stale=%d over_budget=%d failed=%d\n",
+					res.Epoch, res.Renewed, res.Cost, res.Stale, res.OverBudget, len(res.Failed))
+				for _, h := range res.Failed {
+					fmt.Fprintf(w, "failed %d\n", h)
+				}
+			}); err != nil {
+				return err
+			}
+			if len(res.Failed) > 0 {
+				return fmt.Errorf("%d blocks couldn't be renewed", len(res.Failed))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// walrusTools are the clients used by the Walrus maintenance commands.
+type walrusTools struct {
+	cfg       *config.Config
+	logger    zerolog.Logger
+	db        *store.DB
+	btcClient clients.BTCClient
+	walrus    *bitcoinspv.WalrusHandler
+}
+
+// initWalrusTools opens the state DB, which must be configured, the Bitcoin node client and
+// the Walrus client of the config file. Walrus is used even when the relayer doesn't store
+// new blocks.
+func initWalrusTools(cfgFile string) (*walrusTools, error) {
+	cfg, rootLogger, err := initConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Relayer.StateDBFile == "" {
+		return nil, errors.New("state-db-file is not configured")
+	}
+	walrusCfg := cfg.Relayer
+	walrusCfg.StoreBlocksInWalrus = true
+	wh, err := initWalrusHandler(&walrusCfg, rootLogger)
+	if err != nil {
+		return nil, err
+	}
+	db, err := initStateStore(cfg, rootLogger)
+	if err != nil {
+		return nil, err
+	}
+	btcClient, err := initBTCClient(cfg, rootLogger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &walrusTools{cfg: cfg, logger: rootLogger, db: db, btcClient: btcClient, walrus: wh}, nil
+}
+
+func (t *walrusTools) close() {
+	t.btcClient.Stop()
+	t.db.Close()
+}
+
+// openStateStore opens the state DB of the config file, which must be configured.
+func openStateStore(cfgFile string) (*store.DB, error) {
+	cfg, rootLogger, err := initConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Relayer.StateDBFile == "" {
+		return nil, errors.New("state-db-file is not configured")
+	}
+	return initStateStore(cfg, rootLogger)
+}
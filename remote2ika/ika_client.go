@@ -7,7 +7,8 @@ import (
 
 	"github.com/pattonkan/sui-go/sui"
 	"github.com/pattonkan/sui-go/suiclient"
-	"github.com/pattonkan/sui-go/suisigner"
+
+	"github.com/gonative-cc/relayer/signer"
 )
 
 // TransactionDigest is a hash of transaction encoded to a string
@@ -30,7 +31,7 @@ type Client interface {
 // for interacting with Ika
 type client struct {
 	suiCl          *suiclient.ClientImpl
-	Signer         *suisigner.Signer
+	Signer         signer.Signer
 	LcPackage      *sui.PackageId
 	LcModule       string
 	LcFunction     string
@@ -50,7 +51,7 @@ type SignOutputEventData struct {
 // `lc` Bitcoin SPV Light Client
 func NewClient(
 	c *suiclient.ClientImpl,
-	signer *suisigner.Signer,
+	s signer.Signer,
 	spvLC SuiCtrCall,
 	dwallet SuiCtrCall,
 	gasAddr string,
@@ -68,7 +69,7 @@ func NewClient(
 
 	i := &client{
 		suiCl:          c,
-		Signer:         signer,
+		Signer:         s,
 		LcPackage:      lcPackage,
 		LcModule:       spvLC.Module,
 		LcFunction:     spvLC.Function,
@@ -92,7 +93,7 @@ func (c *client) SignReq(
 	// TODO: This function was only tested against dummy implementation of the dwallet module deployed locally.
 	// Once it is ready, test it again
 	req := &suiclient.MoveCallRequest{
-		Signer:    c.Signer.Address,
+		Signer:    c.Signer.Address(),
 		PackageId: c.LcPackage,
 		Module:    c.DWalletModule,
 		Function:  "approve_messages",
@@ -123,10 +124,14 @@ func (c *client) SignReq(
 		ShowObjectChanges: true,
 	}
 
-	response, err := c.suiCl.SignAndExecuteTransaction(ctx, c.Signer, resp.TxBytes, options)
+	response, err := signer.SignAndExecute(ctx, c.suiCl, c.Signer, resp.TxBytes, options)
 	if err != nil {
 		return "", fmt.Errorf("error executing transaction block: %w", err)
 	}
+	if !response.Effects.Data.IsSuccess() {
+		return "", fmt.Errorf("sign transaction %s failed, status: %s, error: %s", response.Digest,
+			response.Effects.Data.V1.Status.Status, response.Effects.Data.V1.Status.Error)
+	}
 	return response.Digest.String(), nil
 
 	/*
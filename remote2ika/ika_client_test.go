@@ -7,9 +7,9 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/pattonkan/sui-go/suiclient"
-	"github.com/pattonkan/sui-go/suisigner"
-	"github.com/pattonkan/sui-go/suisigner/suicrypto"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gonative-cc/relayer/signer"
 )
 
 func TestClient(t *testing.T) {
@@ -26,7 +26,7 @@ func TestClient(t *testing.T) {
 	spvLCFun := "test"
 
 	cl := suiclient.NewClient(localRPC)
-	s, err := suisigner.NewSignerWithMnemonic(localMnemonic, suicrypto.KeySchemeFlagDefault)
+	s, err := signer.NewLocalFromMnemonic(localMnemonic)
 	assert.Nil(t, err)
 
 	client, err := NewClient(
@@ -0,0 +1,198 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pattonkan/sui-go/sui"
+	"golang.org/x/crypto/scrypt"
+)
+
+// aliasesFile is the file of the Sui CLI mapping aliases to the keystore public keys.
+const aliasesFile = "sui.aliases"
+
+// scrypt parameters of new encrypted keystores
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 32
+)
+
+type alias struct {
+	Alias           string `json:"alias"`
+	PublicKeyBase64 string `json:"public_key_base64"`
+}
+
+// ReadKeystore returns the private key of a Sui CLI keystore, selected by address or by alias.
+// The key is in the keystore format: the key scheme flag followed by the 32 bytes private key.
+func ReadKeystore(path, address, aliasName string) ([]byte, error) {
+	if address == "" && aliasName == "" {
+		return nil, ErrNoSignerSelected
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %w", path, err)
+	}
+
+	var addr *sui.Address
+	if address != "" {
+		if addr, err = sui.AddressFromHex(address); err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", address, err)
+		}
+	}
+	var pubKey []byte
+	if aliasName != "" {
+		if pubKey, err = aliasPublicKey(filepath.Join(filepath.Dir(path), aliasesFile), aliasName); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, entry := range entries {
+		key, err := base64.StdEncoding.DecodeString(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keystore %s entry %d: %w", path, i, err)
+		}
+		l, err := newLocalFromKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keystore %s entry %d: %w", path, i, err)
+		}
+		if addr != nil && *l.Address() != *addr {
+			continue
+		}
+		if pubKey != nil && !bytes.Equal(pubKey, append([]byte{key[0]}, l.signer.PublicKeyBytes()...)) {
+			continue
+		}
+		return key, nil
+	}
+	if aliasName != "" {
+		return nil, fmt.Errorf("%w: alias %s", ErrKeyNotFound, aliasName)
+	}
+	return nil, fmt.Errorf("%w: address %s", ErrKeyNotFound, address)
+}
+
+// aliasPublicKey returns the public key, prefixed with the key scheme flag, of a Sui CLI alias.
+func aliasPublicKey(path, name string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []alias
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid aliases file %s: %w", path, err)
+	}
+	for _, a := range aliases {
+		if a.Alias == name {
+			return base64.StdEncoding.DecodeString(a.PublicKeyBase64)
+		}
+	}
+	return nil, fmt.Errorf("%w: alias %s", ErrKeyNotFound, name)
+}
+
+// encryptedKeystore is a private key encrypted with AES-256-GCM, using a key derived from a
+// passphrase with scrypt.
+type encryptedKeystore struct {
+	Version int `json:"version"`
+	// Address is informative: the address of the encrypted key.
+	Address    string `json:"address"`
+	ScryptN    int    `json:"scrypt_n"`
+	ScryptR    int    `json:"scrypt_r"`
+	ScryptP    int    `json:"scrypt_p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptKey encrypts a private key in the keystore format with a passphrase.
+// Returns the JSON encoded encrypted keystore.
+func EncryptKey(key []byte, passphrase string) ([]byte, error) {
+	l, err := newLocalFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	ks := encryptedKeystore{
+		Version: 1,
+		Address: l.Address().String(),
+		ScryptN: scryptN,
+		ScryptR: scryptR,
+		ScryptP: scryptP,
+		Salt:    make([]byte, saltLen),
+	}
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return nil, err
+	}
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, key, nil)
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// DecryptKey decrypts a keystore encrypted by EncryptKey.
+func DecryptKey(data []byte, passphrase string) ([]byte, error) {
+	var ks encryptedKeystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("invalid encrypted keystore: %w", err)
+	}
+	if ks.Version != 1 {
+		return nil, fmt.Errorf("unsupported encrypted keystore version %d", ks.Version)
+	}
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(ks.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted keystore: nonce size %d", len(ks.Nonce))
+	}
+	key, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+func (ks *encryptedKeystore) aead(passphrase string) (cipher.AEAD, error) {
+	dk, err := scrypt.Key([]byte(passphrase), ks.Salt, ks.ScryptN, ks.ScryptR, ks.ScryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the keystore key: %w", err)
+	}
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// expandHome replaces the ~/ prefix of a path with the user home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
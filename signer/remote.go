@@ -0,0 +1,196 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pattonkan/sui-go/sui"
+	"github.com/pattonkan/sui-go/suisigner"
+	"github.com/pattonkan/sui-go/suisigner/suicrypto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Paths of the remote signing service API.
+//
+// POST SignPath signs a transaction: the request is a SignRequest, the response a SignResponse.
+// GET AddressPath returns the address of the service key as an AddressResponse.
+// Errors are returned with a non 2xx status code and an ErrorResponse.
+// When a token is configured, requests are authenticated with the "Authorization: Bearer" header.
+const (
+	SignPath    = "/v1/sign"
+	AddressPath = "/v1/address"
+)
+
+const (
+	// DefaultTokenEnvVar holds the token authenticating to the remote signing service.
+	DefaultTokenEnvVar = "SUI_SIGNER_TOKEN"
+
+	remoteTimeout = 10 * time.Second
+	// rawSignatureLen is the size of the Ed25519, Secp256k1 and Secp256r1 signatures.
+	rawSignatureLen = 64
+)
+
+// ErrRemoteSigner is returned when the remote signing service fails or returns an invalid
+// signature.
+var ErrRemoteSigner = errors.New("remote signer")
+
+// SignRequest is the request of the remote signing service sign endpoint.
+type SignRequest struct {
+	// Address is the address expected to sign, the service must reject other addresses.
+	Address string `json:"address"`
+	// TxBytes is the BCS encoded transaction data.
+	TxBytes []byte `json:"tx_bytes"`
+}
+
+// SignResponse is the response of the remote signing service sign endpoint.
+type SignResponse struct {
+	// Signature is the serialized signature: the key scheme flag, the signature and the
+	// public key.
+	Signature []byte `json:"signature"`
+}
+
+// AddressResponse is the response of the remote signing service address endpoint.
+type AddressResponse struct {
+	Address string `json:"address"`
+}
+
+// ErrorResponse is the body of the remote signing service errors.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Remote is a Signer delegating the signatures to a remote signing service over HTTP. The
+// relayer only holds the address: the private key stays in the signing service.
+type Remote struct {
+	url     string
+	token   string
+	address *sui.Address
+	client  *http.Client
+}
+
+var _ Signer = &Remote{}
+
+// NewRemote creates a Signer for the signing service at url. When address is nil, it's
+// fetched from the service.
+func NewRemote(ctx context.Context, url, token string, address *sui.Address) (*Remote, error) {
+	r := &Remote{
+		url:     strings.TrimSuffix(url, "/"),
+		token:   token,
+		address: address,
+		client:  &http.Client{Timeout: remoteTimeout},
+	}
+	if address == nil {
+		var resp AddressResponse
+		if err := r.do(ctx, http.MethodGet, AddressPath, nil, &resp); err != nil {
+			return nil, err
+		}
+		addr, err := sui.AddressFromHex(resp.Address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid address %q: %w", ErrRemoteSigner, resp.Address, err)
+		}
+		r.address = addr
+	}
+	return r, nil
+}
+
+// Address implements Signer.
+func (r *Remote) Address() *sui.Address {
+	return r.address
+}
+
+// SignTransaction implements Signer. The signature is checked to be made over the transaction
+// by the key of the signer address.
+func (r *Remote) SignTransaction(ctx context.Context, txBytes []byte) (*suisigner.Signature, error) {
+	var resp SignResponse
+	req := SignRequest{Address: r.address.String(), TxBytes: txBytes}
+	if err := r.do(ctx, http.MethodPost, SignPath, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) <= 1+rawSignatureLen {
+		return nil, fmt.Errorf("%w: invalid signature size %d", ErrRemoteSigner, len(resp.Signature))
+	}
+	if addr := signatureAddress(resp.Signature); addr != *r.address {
+		return nil, fmt.Errorf("%w: signature not made by %s", ErrRemoteSigner, r.address)
+	}
+	if !verifySignature(resp.Signature, suisigner.SigningDigest(txBytes, suisigner.IntentTransaction())) {
+		return nil, fmt.Errorf("%w: invalid signature of the transaction", ErrRemoteSigner)
+	}
+	// the sui-go signature is only decoded from JSON
+	data, err := json.Marshal(resp.Signature)
+	if err != nil {
+		return nil, err
+	}
+	var sig suisigner.Signature
+	if err := sig.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteSigner, err)
+	}
+	return &sig, nil
+}
+
+func (r *Remote) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.url+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRemoteSigner, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		return fmt.Errorf("%w: %s %s: %s", ErrRemoteSigner, method, path, e.Error)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: invalid response: %w", ErrRemoteSigner, err)
+	}
+	return nil
+}
+
+// signatureAddress returns the address of the public key of a serialized signature: the key
+// scheme flag, the 64 bytes signature and the public key.
+func signatureAddress(sig []byte) sui.Address {
+	return sui.Address(blake2b.Sum256(append([]byte{sig[0]}, sig[1+rawSignatureLen:]...)))
+}
+
+// verifySignature checks a serialized signature was made over the digest by its public key.
+func verifySignature(sig, digest []byte) bool {
+	raw, pubKey := sig[1:1+rawSignatureLen], sig[1+rawSignatureLen:]
+	switch suicrypto.KeySchemeFlag(sig[0]) {
+	case suicrypto.KeySchemeFlagEd25519:
+		pk, err := suicrypto.Ed25519PubKeyFromBytes(pubKey)
+		return err == nil && pk.Verify(digest, raw)
+	case suicrypto.KeySchemeFlagSecp256k1:
+		pk, err := suicrypto.Secp256k1PubKeyFromBytes(pubKey)
+		return err == nil && pk.Verify(digest, raw)
+	case suicrypto.KeySchemeFlagSecp256r1:
+		pk, err := suicrypto.Secp256r1PubKeyFromBytes(pubKey)
+		return err == nil && pk.Verify(digest, raw)
+	}
+	return false
+}
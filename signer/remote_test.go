@@ -0,0 +1,122 @@
+package signer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pattonkan/sui-go/suisigner"
+	"github.com/pattonkan/sui-go/suisigner/suicrypto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSigner struct {
+	*Local
+	sig *suisigner.Signature
+}
+
+func (s staticSigner) SignTransaction(context.Context, []byte) (*suisigner.Signature, error) {
+	return s.sig, nil
+}
+
+func TestRemote(t *testing.T) {
+	ctx := context.Background()
+	txBytes := []byte("tx data")
+	local, err := newLocalFromKey(testKey(suicrypto.KeySchemeFlagEd25519, 1))
+	require.NoError(t, err)
+	other, err := newLocalFromKey(testKey(suicrypto.KeySchemeFlagEd25519, 2))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewServer(local, "secret", zerolog.Nop()))
+	t.Cleanup(srv.Close)
+
+	t.Run("fetches address and signs", func(t *testing.T) {
+		r, err := NewRemote(ctx, srv.URL+"/", "secret", nil)
+		require.NoError(t, err)
+		assert.Equal(t, local.Address(), r.Address())
+
+		got, err := r.SignTransaction(ctx, txBytes)
+		require.NoError(t, err)
+		want, err := local.SignTransaction(ctx, txBytes)
+		require.NoError(t, err)
+		assert.Equal(t, want.Bytes(), got.Bytes())
+	})
+
+	t.Run("secp256 keys", func(t *testing.T) {
+		for _, flag := range []suicrypto.KeySchemeFlag{suicrypto.KeySchemeFlagSecp256k1, suicrypto.KeySchemeFlagSecp256r1} {
+			key, err := newLocalFromKey(testKey(flag, 3))
+			require.NoError(t, err)
+			srv := httptest.NewServer(NewServer(key, "", zerolog.Nop()))
+			t.Cleanup(srv.Close)
+
+			r, err := NewRemote(ctx, srv.URL, "", nil)
+			require.NoError(t, err)
+			_, err = r.SignTransaction(ctx, txBytes)
+			assert.NoError(t, err, flag.String())
+		}
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		_, err := NewRemote(ctx, srv.URL, "wrong", nil)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+		assert.ErrorContains(t, err, "invalid token")
+	})
+
+	t.Run("address not served", func(t *testing.T) {
+		r, err := NewRemote(ctx, srv.URL, "secret", other.Address())
+		require.NoError(t, err)
+		_, err = r.SignTransaction(ctx, txBytes)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+		assert.ErrorContains(t, err, "unknown address")
+	})
+
+	t.Run("signature of another key", func(t *testing.T) {
+		sig, err := other.SignTransaction(ctx, txBytes)
+		require.NoError(t, err)
+		srv := httptest.NewServer(NewServer(staticSigner{local, sig}, "", zerolog.Nop()))
+		t.Cleanup(srv.Close)
+
+		r, err := NewRemote(ctx, srv.URL, "", local.Address())
+		require.NoError(t, err)
+		_, err = r.SignTransaction(ctx, txBytes)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+		assert.ErrorContains(t, err, "signature not made by")
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		sig, err := local.SignTransaction(ctx, txBytes)
+		require.NoError(t, err)
+		sig.Ed25519SuiSignature.Signature[1] ^= 0xff
+		srv := httptest.NewServer(NewServer(staticSigner{local, sig}, "", zerolog.Nop()))
+		t.Cleanup(srv.Close)
+
+		r, err := NewRemote(ctx, srv.URL, "", local.Address())
+		require.NoError(t, err)
+		_, err = r.SignTransaction(ctx, txBytes)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+		assert.ErrorContains(t, err, "invalid signature of the transaction")
+	})
+
+	t.Run("signature of another transaction", func(t *testing.T) {
+		sig, err := local.SignTransaction(ctx, []byte("other tx"))
+		require.NoError(t, err)
+		srv := httptest.NewServer(NewServer(staticSigner{local, sig}, "", zerolog.Nop()))
+		t.Cleanup(srv.Close)
+
+		r, err := NewRemote(ctx, srv.URL, "", local.Address())
+		require.NoError(t, err)
+		_, err = r.SignTransaction(ctx, txBytes)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+
+		_, err := NewRemote(ctx, srv.URL, "", nil)
+		assert.ErrorIs(t, err, ErrRemoteSigner)
+	})
+}
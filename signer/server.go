@@ -0,0 +1,87 @@
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// maxSignRequestSize limits the sign request body. Sui transactions are at most 128KiB.
+const maxSignRequestSize = 1 << 20
+
+// Server is a reference remote signing service signing with a Signer, to stand in for a
+// dedicated signing host in development and test setups.
+type Server struct {
+	mux    *http.ServeMux
+	signer Signer
+	token  string
+	logger zerolog.Logger
+}
+
+// NewServer creates a signing service for s. When token is not empty, requests must be
+// authenticated with it.
+func NewServer(s Signer, token string, parentLogger zerolog.Logger) *Server {
+	srv := &Server{
+		mux:    http.NewServeMux(),
+		signer: s,
+		token:  token,
+		logger: parentLogger.With().Str("module", "signer_server").Logger(),
+	}
+	srv.mux.HandleFunc("GET "+AddressPath, srv.handleAddress)
+	srv.mux.HandleFunc("POST "+SignPath, srv.handleSign)
+	return srv
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.token != "" {
+		auth := []byte(req.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, req)
+}
+
+func (s *Server) handleAddress(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, AddressResponse{Address: s.signer.Address().String()})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, req *http.Request) {
+	var signReq SignRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxSignRequestSize)).Decode(&signReq); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if signReq.Address != s.signer.Address().String() {
+		writeError(w, http.StatusForbidden, "unknown address "+signReq.Address)
+		return
+	}
+	if len(signReq.TxBytes) == 0 {
+		writeError(w, http.StatusBadRequest, "empty transaction")
+		return
+	}
+
+	sig, err := s.signer.SignTransaction(req.Context(), signReq.TxBytes)
+	if err != nil {
+		s.logger.Err(err).Msg("Failed to sign transaction")
+		writeError(w, http.StatusInternalServerError, "failed to sign transaction")
+		return
+	}
+	s.logger.Info().Str("address", signReq.Address).Int("tx_size", len(signReq.TxBytes)).
+		Msg("Signed transaction")
+	writeJSON(w, http.StatusOK, SignResponse{Signature: sig.Bytes()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, ErrorResponse{Error: msg})
+}
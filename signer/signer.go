@@ -0,0 +1,106 @@
+// Package signer provides the keys signing the Sui transactions of the relayers. Keys are loaded
+// from a source configured outside of the relayer config file: an environment variable, a Sui
+// CLI keystore or a passphrase encrypted keystore. Other signers, e.g. a hardware module, can be
+// used by implementing the Signer interface.
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pattonkan/sui-go/sui"
+	"github.com/pattonkan/sui-go/suiclient"
+	"github.com/pattonkan/sui-go/suisigner"
+	"github.com/pattonkan/sui-go/suisigner/suicrypto"
+)
+
+// Errors
+var (
+	ErrUnknownSource    = errors.New("unknown signer source")
+	ErrKeyNotFound      = errors.New("key not found in keystore")
+	ErrInvalidKey       = errors.New("invalid private key")
+	ErrWrongPassphrase  = errors.New("wrong passphrase or corrupted keystore")
+	ErrMissingEnv       = errors.New("environment variable not set")
+	ErrNoSignerSelected = errors.New("set the address or the alias of the keystore key")
+)
+
+// Signer signs Sui transactions for an address.
+type Signer interface {
+	// Address returns the Sui address of the signing key.
+	Address() *sui.Address
+	// SignTransaction signs the BCS encoded transaction data.
+	SignTransaction(ctx context.Context, txBytes []byte) (*suisigner.Signature, error)
+}
+
+// Local is a Signer holding the private key in memory.
+type Local struct {
+	signer *suisigner.Signer
+}
+
+var _ Signer = &Local{}
+
+// NewLocal creates a Signer from a sui-go signer.
+func NewLocal(s *suisigner.Signer) *Local {
+	return &Local{signer: s}
+}
+
+// NewLocalFromMnemonic creates an Ed25519 Signer from a mnemonic.
+func NewLocalFromMnemonic(mnemonic string) (*Local, error) {
+	s, err := suisigner.NewSignerWithMnemonic(mnemonic, suicrypto.KeySchemeFlagDefault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer from mnemonic: %w", err)
+	}
+	return NewLocal(s), nil
+}
+
+// newLocalFromKey creates a Signer from a private key in the Sui keystore format: the key
+// scheme flag followed by the 32 bytes private key.
+func newLocalFromKey(key []byte) (*Local, error) {
+	if len(key) != 33 {
+		return nil, fmt.Errorf("%w: expected 33 bytes, got %d", ErrInvalidKey, len(key))
+	}
+	flag := suicrypto.KeySchemeFlag(key[0])
+	switch flag {
+	case suicrypto.KeySchemeFlagEd25519, suicrypto.KeySchemeFlagSecp256k1, suicrypto.KeySchemeFlagSecp256r1:
+	default:
+		return nil, fmt.Errorf("%w: unsupported key scheme %s", ErrInvalidKey, flag)
+	}
+	return NewLocal(suisigner.NewSigner(key[1:], flag)), nil
+}
+
+// Address implements Signer.
+func (l *Local) Address() *sui.Address {
+	return l.signer.Address
+}
+
+// SignTransaction implements Signer.
+func (l *Local) SignTransaction(_ context.Context, txBytes []byte) (*suisigner.Signature, error) {
+	return l.signer.SignDigest(txBytes, suisigner.IntentTransaction())
+}
+
+// SignAndExecute signs a transaction with s and executes it, waiting for the local execution.
+// As with the Sui API, a nil error doesn't mean the transaction execution succeeded: the
+// caller must check the effects status.
+func SignAndExecute(
+	ctx context.Context,
+	client *suiclient.ClientImpl,
+	s Signer,
+	txBytes []byte,
+	options *suiclient.SuiTransactionBlockResponseOptions,
+) (*suiclient.SuiTransactionBlockResponse, error) {
+	signature, err := s.SignTransaction(ctx, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	resp, err := client.ExecuteTransactionBlock(ctx, &suiclient.ExecuteTransactionBlockRequest{
+		TxDataBytes: txBytes,
+		Signatures:  []*suisigner.Signature{signature},
+		Options:     options,
+		RequestType: suiclient.TxnRequestTypeWaitForLocalExecution,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute transaction: %w", err)
+	}
+	return resp, nil
+}
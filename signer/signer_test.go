@@ -0,0 +1,133 @@
+package signer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/pattonkan/sui-go/suisigner"
+	"github.com/pattonkan/sui-go/suisigner/suicrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(flag suicrypto.KeySchemeFlag, b byte) []byte {
+	key := make([]byte, 33)
+	key[0] = flag.Byte()
+	for i := 1; i < len(key); i++ {
+		key[i] = b
+	}
+	return key
+}
+
+// writeKeystore writes a Sui CLI keystore and its aliases file.
+func writeKeystore(t *testing.T, keys map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	var entries []string
+	var aliases []alias
+	for name, key := range keys {
+		entries = append(entries, base64.StdEncoding.EncodeToString(key))
+		l, err := newLocalFromKey(key)
+		require.NoError(t, err)
+		pub := append([]byte{key[0]}, l.signer.PublicKeyBytes()...)
+		aliases = append(aliases, alias{Alias: name, PublicKeyBase64: base64.StdEncoding.EncodeToString(pub)})
+	}
+	path := filepath.Join(dir, "sui.keystore")
+	data, err := json.Marshal(entries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	data, err = json.Marshal(aliases)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, aliasesFile), data, 0o600))
+	return path
+}
+
+func TestMnemonicSource(t *testing.T) {
+	s, err := New(Config{Source: SourceMnemonic, Mnemonic: suisigner.TEST_MNEMONIC})
+	require.NoError(t, err)
+	assert.Equal(t, suisigner.TEST_ADDRESS, s.Address())
+
+	sig, err := s.SignTransaction(context.Background(), []byte("tx"))
+	require.NoError(t, err)
+	assert.NotNil(t, sig.Ed25519SuiSignature)
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv(DefaultEnvVar, suisigner.TEST_MNEMONIC)
+	s, err := New(Config{Source: SourceEnv})
+	require.NoError(t, err)
+	assert.Equal(t, suisigner.TEST_ADDRESS, s.Address())
+
+	key := testKey(suicrypto.KeySchemeFlagSecp256k1, 7)
+	data, err := bech32.ConvertBits(key, 8, 5, true)
+	require.NoError(t, err)
+	encoded, err := bech32.Encode(privateKeyHRP, data)
+	require.NoError(t, err)
+	t.Setenv("RELAYER_KEY", encoded)
+	s, err = New(Config{Source: SourceEnv, EnvVar: "RELAYER_KEY"})
+	require.NoError(t, err)
+	expected, err := newLocalFromKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Address(), s.Address())
+
+	_, err = New(Config{Source: SourceEnv, EnvVar: "NOT_SET_SIGNER_KEY"})
+	assert.ErrorIs(t, err, ErrMissingEnv)
+}
+
+func TestKeystoreSource(t *testing.T) {
+	relayerKey := testKey(suicrypto.KeySchemeFlagEd25519, 1)
+	otherKey := testKey(suicrypto.KeySchemeFlagSecp256k1, 2)
+	path := writeKeystore(t, map[string][]byte{"relayer": relayerKey, "other": otherKey})
+	relayer, err := newLocalFromKey(relayerKey)
+	require.NoError(t, err)
+
+	s, err := New(Config{Source: SourceKeystore, Keystore: path, Alias: "relayer"})
+	require.NoError(t, err)
+	assert.Equal(t, relayer.Address(), s.Address())
+
+	s, err = New(Config{Source: SourceKeystore, Keystore: path, Address: relayer.Address().String()})
+	require.NoError(t, err)
+	assert.Equal(t, relayer.Address(), s.Address())
+
+	_, err = New(Config{Source: SourceKeystore, Keystore: path, Alias: "unknown"})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = New(Config{Source: SourceKeystore, Keystore: path, Address: suisigner.TEST_ADDRESS.String()})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = New(Config{Source: SourceKeystore, Keystore: path})
+	assert.ErrorIs(t, err, ErrNoSignerSelected)
+}
+
+func TestEncryptedKeystoreSource(t *testing.T) {
+	key := testKey(suicrypto.KeySchemeFlagEd25519, 3)
+	data, err := EncryptKey(key, "correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), base64.StdEncoding.EncodeToString(key))
+	path := filepath.Join(t.TempDir(), "relayer.key")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	expected, err := newLocalFromKey(key)
+	require.NoError(t, err)
+
+	t.Setenv(DefaultPassphraseEnvVar, "correct horse")
+	s, err := New(Config{Source: SourceEncryptedKeystore, Keystore: path})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Address(), s.Address())
+
+	t.Setenv(DefaultPassphraseEnvVar, "wrong")
+	_, err = New(Config{Source: SourceEncryptedKeystore, Keystore: path})
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestInvalidSource(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorIs(t, err, ErrUnknownSource)
+
+	_, err = newLocalFromKey(testKey(suicrypto.KeySchemeFlagMultiSig, 1))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = newLocalFromKey([]byte{0, 1, 2})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
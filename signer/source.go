@@ -0,0 +1,167 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/pattonkan/sui-go/sui"
+)
+
+// Signer sources
+const (
+	// SourceMnemonic reads the mnemonic from the config file. Discouraged: the key is stored
+	// in cleartext next to the other settings.
+	SourceMnemonic = "mnemonic"
+	// SourceEnv reads a mnemonic or a `suiprivkey` private key from an environment variable.
+	SourceEnv = "env"
+	// SourceKeystore reads the key from a Sui CLI keystore file.
+	SourceKeystore = "keystore"
+	// SourceEncryptedKeystore reads the key from a keystore file encrypted with a passphrase.
+	SourceEncryptedKeystore = "encrypted-keystore"
+	// SourceRemote delegates the signatures to a remote signing service: the key never
+	// reaches the relayer.
+	SourceRemote = "remote"
+)
+
+const (
+	// DefaultEnvVar holds the key of the env source.
+	DefaultEnvVar = "SUI_SIGNER_KEY"
+	// DefaultPassphraseEnvVar holds the passphrase of the encrypted keystore.
+	DefaultPassphraseEnvVar = "SUI_KEYSTORE_PASSPHRASE"
+
+	privateKeyHRP = "suiprivkey"
+)
+
+// Config selects the source of the signing key.
+type Config struct {
+	// Source is one of the Source* constants.
+	Source string `mapstructure:"source"`
+	// EnvVar is the environment variable of the env source, DefaultEnvVar if empty.
+	EnvVar string `mapstructure:"env_var"`
+	// Keystore is the path of the Sui CLI keystore or of the encrypted keystore.
+	Keystore string `mapstructure:"keystore"`
+	// Address or Alias select the key of the Sui CLI keystore. The alias is looked up in the
+	// sui.aliases file next to the keystore.
+	Address string `mapstructure:"address"`
+	Alias   string `mapstructure:"alias"`
+	// PassphraseEnvVar is the environment variable with the passphrase of the encrypted
+	// keystore, DefaultPassphraseEnvVar if empty.
+	PassphraseEnvVar string `mapstructure:"passphrase_env_var"`
+	// URL is the base URL of the remote signing service. Address, if set, is the expected
+	// address of the service key, otherwise it's fetched from the service.
+	URL string `mapstructure:"url"`
+	// TokenEnvVar is the environment variable with the token of the remote signing service,
+	// DefaultTokenEnvVar if empty. The token is optional.
+	TokenEnvVar string `mapstructure:"token_env_var"`
+	// Mnemonic of the mnemonic source. It's not read from the signer config section.
+	Mnemonic string `mapstructure:"-"`
+}
+
+// New loads the key of the configured source.
+func New(cfg Config) (Signer, error) {
+	switch cfg.Source {
+	case SourceMnemonic:
+		return NewLocalFromMnemonic(cfg.Mnemonic)
+	case SourceEnv:
+		name := cfg.EnvVar
+		if name == "" {
+			name = DefaultEnvVar
+		}
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingEnv, name)
+		}
+		return parseKey(value)
+	case SourceKeystore:
+		key, err := ReadKeystore(cfg.Keystore, cfg.Address, cfg.Alias)
+		if err != nil {
+			return nil, err
+		}
+		return newLocalFromKey(key)
+	case SourceEncryptedKeystore:
+		passphrase, err := Passphrase(cfg.PassphraseEnvVar)
+		if err != nil {
+			return nil, err
+		}
+		path, err := expandHome(cfg.Keystore)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := DecryptKey(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return newLocalFromKey(key)
+	case SourceRemote:
+		return newRemoteFromConfig(cfg)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownSource, cfg.Source)
+}
+
+// Passphrase returns the passphrase of the encrypted keystore from the environment variable
+// name, or DefaultPassphraseEnvVar if name is empty.
+func Passphrase(name string) (string, error) {
+	if name == "" {
+		name = DefaultPassphraseEnvVar
+	}
+	passphrase := os.Getenv(name)
+	if passphrase == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingEnv, name)
+	}
+	return passphrase, nil
+}
+
+func newRemoteFromConfig(cfg Config) (*Remote, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("%w: url not set", ErrRemoteSigner)
+	}
+	var addr *sui.Address
+	if cfg.Address != "" {
+		var err error
+		if addr, err = sui.AddressFromHex(cfg.Address); err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", cfg.Address, err)
+		}
+	}
+	name := cfg.TokenEnvVar
+	if name == "" {
+		name = DefaultTokenEnvVar
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	return NewRemote(ctx, cfg.URL, os.Getenv(name), addr)
+}
+
+// parseKey parses a Bech32 `suiprivkey` private key, as exported by `sui keytool export`,
+// or a mnemonic.
+func parseKey(value string) (Signer, error) {
+	if !strings.HasPrefix(value, privateKeyHRP+"1") {
+		return NewLocalFromMnemonic(value)
+	}
+	key, err := decodePrivateKey(value)
+	if err != nil {
+		return nil, err
+	}
+	return newLocalFromKey(key)
+}
+
+func decodePrivateKey(value string) ([]byte, error) {
+	hrp, data, err := bech32.DecodeNoLimit(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	if hrp != privateKeyHRP {
+		return nil, fmt.Errorf("%w: unexpected prefix %s", ErrInvalidKey, hrp)
+	}
+	key, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	return key, nil
+}